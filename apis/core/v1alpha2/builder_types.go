@@ -40,8 +40,16 @@ type BuilderSpec struct {
 	//
 	// +optional
 	BuilderCredentials *v1.LocalObjectReference `json:"builderCredentials,omitempty"`
+	// Engine is the build engine used to build the image, Shipwright or Kaniko, default to Shipwright.
+	//
+	// +optional
+	Engine *Engine `json:"engine,omitempty"`
 	// The configuration for `Shipwright` build engine.
 	Shipwright *ShipwrightEngine `json:"shipwright,omitempty"`
+	// The configuration for `Kaniko` build engine.
+	//
+	// +optional
+	Kaniko *KanikoEngine `json:"kaniko,omitempty"`
 	// Git repository info of a function
 	SrcRepo *GitRepo `json:"srcRepo"`
 	// Function image name
@@ -70,6 +78,40 @@ type BuilderStatus struct {
 	State string `json:"state,omitempty"`
 	// Associate resources.
 	ResourceRef map[string]string `json:"resourceRef,omitempty"`
+	// Reason is a brief CamelCase string that describes the result of the build.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message is a human-readable message about the result of the build.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// TaskRun is the name of the TaskRun which runs the build.
+	// +optional
+	TaskRun string `json:"taskRun,omitempty"`
+	// Pod is the name of the pod in which the build failed.
+	// +optional
+	Pod string `json:"pod,omitempty"`
+	// FailedStep is the name of the container of the build step which failed.
+	// +optional
+	FailedStep string `json:"failedStep,omitempty"`
+	// Digest is the digest of the image built, e.g. `sha256:...`.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+	// StartTime is the time the build started, the timeout of the build is counted from it.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the build completed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Conditions describe the state of the builder in a standard way.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// ObservedGeneration is the generation of the builder observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -78,6 +120,7 @@ type BuilderStatus struct {
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
 //+kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+//+kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.reason`
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // Builder is the Schema for the builders API
@@ -105,3 +148,16 @@ func init() {
 func (s *BuilderStatus) IsCompleted() bool {
 	return s.State != "" && s.State != Building
 }
+
+// HashInclude keeps the unset fields added after the first release out of the hash of the spec,
+// so that upgrading the controller does not rebuild the existing functions.
+func (s BuilderSpec) HashInclude(field string, v interface{}) (bool, error) {
+	switch field {
+	case "Engine":
+		return s.Engine != nil, nil
+	case "Kaniko":
+		return s.Kaniko != nil, nil
+	default:
+		return true, nil
+	}
+}
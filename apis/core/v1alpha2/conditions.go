@@ -0,0 +1,193 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// BuildSucceeded indicates whether the function image had been built.
+	BuildSucceeded = "BuildSucceeded"
+	// ServingReady indicates whether the serving is running.
+	ServingReady = "ServingReady"
+	// Ready indicates whether the resource is ready, it is the condition used by `kubectl wait` and GitOps tools.
+	Ready = "Ready"
+
+	// ServiceResolved indicates whether the service of the ingress controller or the Gateway of the domain exists.
+	ServiceResolved = "ServiceResolved"
+	// ServiceCreated indicates whether the ExternalName service of the domain is created.
+	ServiceCreated = "ServiceCreated"
+
+	Pending = "Pending"
+)
+
+// SyncConditions updates the conditions of the builder according to its state.
+func (s *BuilderStatus) SyncConditions(generation int64) {
+	status, reason, message := buildCondition(s.State)
+	reason, message = override(reason, message, s.Reason, s.Summary())
+	setCondition(&s.Conditions, BuildSucceeded, status, reason, message, generation)
+	setCondition(&s.Conditions, Ready, status, reason, message, generation)
+}
+
+// SyncConditions updates the conditions of the serving according to its state.
+func (s *ServingStatus) SyncConditions(generation int64) {
+	status, reason, message := servingCondition(s.State)
+	setCondition(&s.Conditions, ServingReady, status, reason, message, generation)
+	setCondition(&s.Conditions, Ready, status, reason, message, generation)
+}
+
+// SyncConditions updates the conditions of the function according to the state of build and serving.
+func (s *FunctionStatus) SyncConditions(generation int64) {
+	buildStatus, buildReason, buildMessage := metav1.ConditionUnknown, Pending, "Builder has not been created"
+	if s.Build != nil && s.Build.State != "" {
+		buildStatus, buildReason, buildMessage = buildCondition(s.Build.State)
+		buildReason, buildMessage = override(buildReason, buildMessage, s.Build.Reason, s.Build.Message)
+	}
+	setCondition(&s.Conditions, BuildSucceeded, buildStatus, buildReason, buildMessage, generation)
+
+	servingStatus, servingReason, servingMessage := metav1.ConditionUnknown, Pending, "Serving has not been created"
+	if s.Serving != nil && s.Serving.State != "" {
+		servingStatus, servingReason, servingMessage = servingCondition(s.Serving.State)
+	}
+	setCondition(&s.Conditions, ServingReady, servingStatus, servingReason, servingMessage, generation)
+
+	switch {
+	case buildStatus == metav1.ConditionFalse:
+		setCondition(&s.Conditions, Ready, buildStatus, buildReason, buildMessage, generation)
+	case servingStatus == metav1.ConditionFalse:
+		setCondition(&s.Conditions, Ready, servingStatus, servingReason, servingMessage, generation)
+	case buildStatus == metav1.ConditionUnknown:
+		setCondition(&s.Conditions, Ready, buildStatus, buildReason, buildMessage, generation)
+	case servingStatus == metav1.ConditionUnknown:
+		setCondition(&s.Conditions, Ready, servingStatus, servingReason, servingMessage, generation)
+	default:
+		setCondition(&s.Conditions, Ready, metav1.ConditionTrue, servingReason, "Function is ready", generation)
+	}
+}
+
+// SetCondition sets a condition of the domain.
+func (s *DomainStatus) SetCondition(conditionType string, status metav1.ConditionStatus, reason, message string, generation int64) {
+	setCondition(&s.Conditions, conditionType, status, reason, message, generation)
+}
+
+// SyncConditions updates the Ready condition of the domain according to its other conditions.
+func (s *DomainStatus) SyncConditions(generation int64) {
+	for _, t := range []string{ServiceResolved, ServiceCreated} {
+		c := meta.FindStatusCondition(s.Conditions, t)
+		if c == nil {
+			setCondition(&s.Conditions, Ready, metav1.ConditionUnknown, Pending, fmt.Sprintf("Condition %s is not reported", t), generation)
+			return
+		}
+
+		if c.Status != metav1.ConditionTrue {
+			setCondition(&s.Conditions, Ready, c.Status, c.Reason, c.Message, generation)
+			return
+		}
+	}
+
+	setCondition(&s.Conditions, Ready, metav1.ConditionTrue, "Ready", "Domain is ready", generation)
+}
+
+// Summary returns the message of the build result, when the build did not succeed,
+// it points to the TaskRun, pod and container in which the logs of the build can be found.
+func (s *BuilderStatus) Summary() string {
+	if s.State == Succeeded {
+		return s.Message
+	}
+
+	var refs []string
+	if s.TaskRun != "" {
+		refs = append(refs, fmt.Sprintf("taskRun: %s", s.TaskRun))
+	}
+	if s.Pod != "" {
+		refs = append(refs, fmt.Sprintf("pod: %s", s.Pod))
+	}
+	if s.FailedStep != "" {
+		refs = append(refs, fmt.Sprintf("container: %s", s.FailedStep))
+	}
+
+	switch {
+	case len(refs) == 0:
+		return s.Message
+	case s.Message == "":
+		return strings.Join(refs, ", ")
+	default:
+		return fmt.Sprintf("%s (%s)", s.Message, strings.Join(refs, ", "))
+	}
+}
+
+func buildCondition(state string) (metav1.ConditionStatus, string, string) {
+	switch state {
+	case "", Created, Building:
+		return metav1.ConditionUnknown, reason(state), "Build is in progress"
+	case Succeeded:
+		return metav1.ConditionTrue, Succeeded, "Image had been built"
+	case Skipped:
+		return metav1.ConditionTrue, Skipped, "Build is skipped"
+	default:
+		return metav1.ConditionFalse, reason(state), fmt.Sprintf("Build is %s", state)
+	}
+}
+
+func servingCondition(state string) (metav1.ConditionStatus, string, string) {
+	switch state {
+	case "", Created, Starting:
+		return metav1.ConditionUnknown, reason(state), "Serving is starting"
+	case Running:
+		return metav1.ConditionTrue, Running, "Serving is running"
+	case Skipped:
+		return metav1.ConditionTrue, Skipped, "Serving is skipped"
+	default:
+		return metav1.ConditionFalse, reason(state), fmt.Sprintf("Serving is %s", state)
+	}
+}
+
+// Use the reason and message reported by the resource in place of the ones derived from the state.
+func override(reason, message, reportedReason, reportedMessage string) (string, string) {
+	if reportedReason != "" {
+		reason = reportedReason
+	}
+	if reportedMessage != "" {
+		message = reportedMessage
+	}
+
+	return reason, message
+}
+
+// The reason of a condition must not be empty.
+func reason(state string) string {
+	if state == "" {
+		return Pending
+	}
+
+	return state
+}
+
+func setCondition(conditions *[]metav1.Condition, conditionType string, status metav1.ConditionStatus, reason, message string, generation int64) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
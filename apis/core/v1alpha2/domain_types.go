@@ -17,9 +17,17 @@ limitations under the License.
 package v1alpha2
 
 import (
+	"fmt"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// DefaultDomainAnnotation marks a Domain as the default Domain of the cluster when it is "true",
+	// it is used by the functions which do not reference a Domain.
+	DefaultDomainAnnotation = "openfunction.io/default-domain"
+)
+
 type IngressControllerService struct {
 	// Name of the Ingress controller service.
 	Name string `json:"name"`
@@ -27,6 +35,34 @@ type IngressControllerService struct {
 	Namespace string `json:"namespace"`
 	// Port of the Ingress controller service, default is 80.
 	Port int32 `json:"port,omitempty"`
+	// HTTPSPort is the https port of the Ingress controller service, default is 443.
+	// It takes effect when TLS is enabled.
+	HTTPSPort int32 `json:"httpsPort,omitempty"`
+}
+
+// IssuerReference references a cert-manager issuer.
+type IssuerReference struct {
+	// Name of the issuer.
+	Name string `json:"name"`
+	// Kind of the issuer, `Issuer` or `ClusterIssuer`, default is `ClusterIssuer`.
+	//
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+type IngressTLS struct {
+	// SecretName is the name of the secret which holds the TLS certificate.
+	// The secret must exist in the namespace of each function, or is created there by cert-manager if `Issuer` is set.
+	SecretName string `json:"secretName"`
+	// Hosts are the hosts included in the TLS certificate, it is required by cert-manager.
+	//
+	// +optional
+	Hosts []string `json:"hosts,omitempty"`
+	// Issuer is the cert-manager issuer which issues the certificate.
+	//
+	// +optional
+	Issuer *IssuerReference `json:"issuer,omitempty"`
 }
 type IngressConfig struct {
 	// Annotations for Ingress.
@@ -38,21 +74,124 @@ type IngressConfig struct {
 	// IngressClassName is the name of the IngressClass cluster resource. The
 	// associated IngressClass defines which controller will implement the resource.
 	IngressClassName string `json:"ingressClassName"`
+	// TLS enables https for the functions, the function urls use https when it is set.
+	//
+	// +optional
+	TLS *IngressTLS `json:"tls,omitempty"`
+}
+
+type RoutingMode string
+
+const (
+	// PathRouting routes the functions by the path `/{function-namespace}/{function-name}`.
+	PathRouting RoutingMode = "Path"
+	// HostRouting routes the functions by the host `{function-name}.{function-namespace}.{host}`,
+	// the functions are served at `/`.
+	HostRouting RoutingMode = "Host"
+)
+
+type GatewayConfig struct {
+	// Name of the Gateway.
+	Name string `json:"name"`
+	// Namespace of the Gateway.
+	Namespace string `json:"namespace"`
+	// Service of the Gateway.
+	Service IngressControllerService `json:"service"`
+	// TLS indicates that the listeners of the Gateway terminate TLS, the function urls use https when it is true.
+	//
+	// +optional
+	TLS bool `json:"tls,omitempty"`
 }
 
 // DomainSpec defines the desired state of a Domain
 type DomainSpec struct {
-	// Ingress configuration.
-	Ingress IngressConfig `json:"ingress"`
+	// Ingress configuration, it is ignored if `Gateway` is set.
+	//
+	// +optional
+	Ingress IngressConfig `json:"ingress,omitempty"`
+	// Gateway is the Gateway API Gateway through which the functions are accessed.
+	// If it is set, an HTTPRoute is created for each function instead of the ingress.
+	// In `Path` routing mode, the HTTPRoutes can not strip the path prefix, so the functions receive
+	// the requests with the prefix `/{function-namespace}/{function-name}`.
+	//
+	// +optional
+	Gateway *GatewayConfig `json:"gateway,omitempty"`
+	// Routing is the mode in which the functions are routed, `Path` or `Host`, default is `Path`.
+	// In `Host` mode, each function uses its own ingress.
+	//
+	// +kubebuilder:validation:Enum=Path;Host
+	// +optional
+	Routing RoutingMode `json:"routing,omitempty"`
+	// Host is the base host of the functions, it is required in `Host` routing mode.
+	//
+	// +optional
+	Host string `json:"host,omitempty"`
+}
+
+// UseGateway returns true if the functions are accessed through a Gateway.
+func (d *Domain) UseGateway() bool {
+	return d.Spec.Gateway != nil
+}
+
+// GetService returns the service of the ingress controller or the Gateway.
+func (d *Domain) GetService() IngressControllerService {
+	if d.UseGateway() {
+		return d.Spec.Gateway.Service
+	}
+
+	return d.Spec.Ingress.Service
+}
+
+// UseTLS returns true if the functions are served over https.
+func (d *Domain) UseTLS() bool {
+	if d.UseGateway() {
+		return d.Spec.Gateway.TLS
+	}
+
+	return d.Spec.Ingress.TLS != nil
+}
+
+// IsHostRouting returns true if the functions are routed by host.
+func (d *Domain) IsHostRouting() bool {
+	return d.Spec.Routing == HostRouting
+}
+
+// FunctionHost returns the host of the function in `Host` routing mode.
+func (d *Domain) FunctionHost(name, namespace string) string {
+	return fmt.Sprintf("%s.%s.%s", name, namespace, d.Spec.Host)
 }
 
 // DomainStatus defines the observed state of Domain
 type DomainStatus struct {
+	// Conditions describe whether the service of the ingress controller or the Gateway exists
+	// and whether the service of the domain is created.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// ObservedGeneration is the generation of the domain observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// URL is the base url of the functions accessed through the domain.
+	// +optional
+	URL string `json:"url,omitempty"`
+	// FunctionCount is the number of functions routed through the domain.
+	// +optional
+	FunctionCount int32 `json:"functionCount,omitempty"`
+	// Functions are the functions routed through the domain, in the form of {namespace}/{name}.
+	// +optional
+	Functions []string `json:"functions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:storageversion
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.url`
+//+kubebuilder:printcolumn:name="Functions",type=integer,JSONPath=`.status.functionCount`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Domain define a unified entry for function, user can access function through it.
 type Domain struct {
@@ -0,0 +1,85 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var domainlog = logf.Log.WithName("domain-resource")
+
+func (r *Domain) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-core-openfunction-io-v1alpha2-domain,mutating=false,failurePolicy=fail,groups=core.openfunction.io,resources=domains,verbs=create;update,versions=v1alpha2,name=vdomains.of.io,sideEffects=None,admissionReviewVersions=v1
+var _ webhook.Validator = &Domain{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Domain) ValidateCreate() error {
+	domainlog.Info("validate create", "name", r.Name)
+	return r.invalid(r.validate())
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Domain) ValidateUpdate(old runtime.Object) error {
+	domainlog.Info("validate update", "name", r.Name)
+
+	// Do not block removing the finalizers of the objects created before the validation was introduced.
+	if r.DeletionTimestamp != nil {
+		return nil
+	}
+
+	allErrs := r.validate()
+	if o, ok := old.(*Domain); ok {
+		allErrs = ratchet(allErrs, o.validate())
+	}
+
+	return r.invalid(allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Domain) ValidateDelete() error {
+	return nil
+}
+
+func (r *Domain) validate() field.ErrorList {
+	spec := field.NewPath("spec")
+
+	var allErrs field.ErrorList
+	if r.IsHostRouting() && r.Spec.Host == "" {
+		allErrs = append(allErrs, field.Required(spec.Child("host"), "host must be set in Host routing mode"))
+	}
+
+	return allErrs
+}
+
+func (r *Domain) invalid(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(GroupVersion.WithKind("Domain").GroupKind(), r.Name, allErrs)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import "testing"
+
+func TestDomainValidate(t *testing.T) {
+	gateway := &GatewayConfig{Name: "gateway", Namespace: "gateway"}
+
+	tests := []struct {
+		name       string
+		spec       DomainSpec
+		wantFields []string
+	}{
+		{
+			name: "path routing through an ingress",
+			spec: DomainSpec{},
+		},
+		{
+			name: "host routing through an ingress",
+			spec: DomainSpec{Routing: HostRouting, Host: "example.com"},
+		},
+		{
+			name:       "host routing without host",
+			spec:       DomainSpec{Routing: HostRouting},
+			wantFields: []string{"spec.host"},
+		},
+		{
+			name: "host routing through a gateway",
+			spec: DomainSpec{Gateway: gateway, Routing: HostRouting, Host: "example.com"},
+		},
+		{
+			name: "path routing through a gateway",
+			spec: DomainSpec{Gateway: gateway, Routing: PathRouting},
+		},
+		{
+			name:       "host routing through a gateway without host",
+			spec:       DomainSpec{Gateway: gateway, Routing: HostRouting},
+			wantFields: []string{"spec.host"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Domain{Spec: tt.spec}
+			assertFields(t, d.validate(), tt.wantFields)
+		})
+	}
+}
+
+func TestDomainValidateUpdate(t *testing.T) {
+	gateway := &GatewayConfig{Name: "gateway", Namespace: "gateway"}
+
+	// The domain was created before the validation was introduced.
+	old := &Domain{Spec: DomainSpec{Routing: HostRouting}}
+
+	if err := (&Domain{Spec: DomainSpec{Gateway: gateway, Routing: HostRouting}}).ValidateUpdate(old); err != nil {
+		t.Errorf("ValidateUpdate() of an existing error = %v, want nil", err)
+	}
+	if err := (&Domain{Spec: DomainSpec{Routing: HostRouting}}).ValidateUpdate(&Domain{}); err == nil {
+		t.Errorf("ValidateUpdate() of a new error = nil, want error")
+	}
+}
@@ -17,8 +17,11 @@ limitations under the License.
 package v1alpha2
 
 import (
+	"strings"
+
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type GitRepo struct {
@@ -33,6 +36,33 @@ type GitRepo struct {
 	//
 	// +optional
 	Credentials *v1.LocalObjectReference `json:"credentials,omitempty"`
+	// AutoRebuild rebuilds the function when the revision moves to a new commit,
+	// the revision is periodically resolved to a commit, only http(s) repositories are supported.
+	// It is ignored by the hash of the builder, so that changing it does not trigger a build.
+	//
+	// +optional
+	AutoRebuild *AutoRebuild `json:"autoRebuild,omitempty" hash:"ignore"`
+}
+
+type AutoRebuild struct {
+	// Interval is the interval between two resolutions of the revision, defaults to 5m.
+	//
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// SourceStatus describes the source of the function, it is recorded when `spec.build.srcRepo.autoRebuild` is set.
+type SourceStatus struct {
+	// Url is the url of the repository which was resolved.
+	Url string `json:"url,omitempty"`
+	// Revision is the revision which was resolved.
+	Revision string `json:"revision,omitempty"`
+	// Commit is the commit the revision was resolved to.
+	Commit string `json:"commit,omitempty"`
+	// BuiltCommit is the commit of the last successful build.
+	BuiltCommit string `json:"builtCommit,omitempty"`
+	// LastResolveTime is the time the revision was last resolved to a different commit.
+	LastResolveTime *metav1.Time `json:"lastResolveTime,omitempty"`
 }
 
 func (gr *GitRepo) Init() {
@@ -44,6 +74,7 @@ func (gr *GitRepo) Init() {
 
 type Language string
 type Runtime string
+type Engine string
 
 const (
 	BuildPhase             = "Build"
@@ -59,6 +90,10 @@ const (
 	UnknownRuntime         = "UnknownRuntime"
 	Knative        Runtime = "Knative"
 	OpenFuncAsync  Runtime = "OpenFuncAsync"
+	Plain          Runtime = "Plain"
+	KedaHTTP       Runtime = "KedaHTTP"
+	Shipwright     Engine  = "Shipwright"
+	Kaniko         Engine  = "Kaniko"
 )
 
 type Strategy struct {
@@ -79,7 +114,28 @@ type ShipwrightEngine struct {
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
+// KanikoEngine builds the image from the Dockerfile with a Job running the Kaniko executor,
+// it does not need Shipwright and Tekton to be installed.
+type KanikoEngine struct {
+	// Image is the image of the Kaniko executor, default to `builder.kaniko.image` in the OpenFunction config.
+	//
+	// +optional
+	Image *string `json:"image,omitempty"`
+	// GitImage is the image used to clone the source code, default to `builder.kaniko.gitImage` in the OpenFunction config.
+	//
+	// +optional
+	GitImage *string `json:"gitImage,omitempty"`
+	// Args are the additional arguments passed to the Kaniko executor, such as `--cache=true`.
+	//
+	// +optional
+	Args []string `json:"args,omitempty"`
+}
+
 type BuildImpl struct {
+	// Engine is the build engine used to build the image, Shipwright or Kaniko, default to Shipwright.
+	//
+	// +optional
+	Engine *Engine `json:"engine,omitempty"`
 	// Builder refers to the image containing the build tools to build the source code.
 	//
 	// +optional
@@ -91,12 +147,17 @@ type BuildImpl struct {
 	BuilderCredentials *v1.LocalObjectReference `json:"builderCredentials,omitempty"`
 	// The configuration for the `Shipwright` build engine.
 	Shipwright *ShipwrightEngine `json:"shipwright,omitempty"`
+	// The configuration for the `Kaniko` build engine.
+	//
+	// +optional
+	Kaniko *KanikoEngine `json:"kaniko,omitempty"`
 	// Params is a list of key/value that could be used to set strategy parameters.
 	// When using _params_, users should avoid:
 	// Defining a parameter name that doesn't match one of the `spec.parameters` defined in the `BuildStrategy`.
 	// Defining a parameter name that collides with the Shipwright reserved parameters including BUILDER_IMAGE,DOCKERFILE,CONTEXT_DIR and any name starting with shp-.
 	Params map[string]string `json:"params,omitempty"`
 	// Environment variables to pass to the builder.
+	// They are passed as build arguments when the engine is Kaniko.
 	Env map[string]string `json:"env,omitempty"`
 	// Function Source code repository
 	SrcRepo *GitRepo `json:"srcRepo"`
@@ -111,7 +172,7 @@ type BuildImpl struct {
 }
 
 type ServingImpl struct {
-	// Function runtime such as Knative or OpenFuncAsync.
+	// Function runtime such as Knative, OpenFuncAsync, Plain or KedaHTTP.
 	Runtime *Runtime `json:"runtime"`
 	// Parameters to pass to the serving.
 	// All parameters will be injected into the pod as environment variables.
@@ -119,6 +180,12 @@ type ServingImpl struct {
 	Params map[string]string `json:"params,omitempty"`
 	// Parameters of asyncFunc runtime, must not be nil when runtime is OpenFuncAsync.
 	OpenFuncAsync *OpenFuncAsyncRuntime `json:"openFuncAsync,omitempty"`
+	// Parameters of Plain runtime, only take effect when runtime is Plain.
+	// +optional
+	Plain *PlainRuntime `json:"plain,omitempty"`
+	// Parameters of KedaHTTP runtime, only take effect when runtime is KedaHTTP.
+	// +optional
+	KedaHTTP *KedaHTTPRuntime `json:"kedaHTTP,omitempty"`
 	// Template describes the pods that will be created.
 	// The container named `function` is the container which is used to run the image built by the builder.
 	// If it is not set, the controller will automatically add one.
@@ -128,10 +195,43 @@ type ServingImpl struct {
 	//
 	// +optional
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Rollout defines how to shift the traffic from the previous serving to a new one, only take effect with Knative and Plain runtime.
+	// If it is not set, all traffic will be cut over to the new serving once it is running.
+	// When both servings use the Knative runtime, the traffic is split between their revisions by the traffic targets
+	// of the new Knative service. Otherwise it falls back to a canary ingress of the nginx ingress controller,
+	// or to the weighted backends of the HTTPRoute when the domain uses a Gateway.
+	//
+	// +optional
+	Rollout *RolloutPolicy `json:"rollout,omitempty"`
+	// AutoRollback determines whether to roll back to the last successful serving
+	// when a new serving failed or timed out.
+	//
+	// +optional
+	AutoRollback bool `json:"autoRollback,omitempty"`
+}
+
+type RolloutStep struct {
+	// Weight is the percentage of traffic routed to the new serving in this step.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight"`
+	// Pause is the amount of time to stay in this step before moving to the next one.
+	// The next step starts immediately if it is not set.
+	//
+	// +optional
+	Pause *metav1.Duration `json:"pause,omitempty"`
+}
+
+type RolloutPolicy struct {
+	// Steps to shift the traffic to the new serving.
+	// All traffic will be cut over to the new serving after the last step, and the previous serving will be deleted.
+	Steps []RolloutStep `json:"steps,omitempty"`
 }
 
 type ServiceImpl struct {
-	// Annotations for Ingress. Take effect when `UseStandaloneIngress` is true.
+	// Annotations for Ingress. Take effect when the function uses its own ingress,
+	// that is `UseStandaloneIngress` is true or the Domain is in `Host` routing mode.
 	//
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
@@ -141,6 +241,33 @@ type ServiceImpl struct {
 	//
 	// +optional
 	UseStandaloneIngress bool `json:"UseStandaloneIngress,omitempty"`
+	// DomainRef references the Domain through which the function is accessed, `status.url` is the url on this Domain.
+	// The default Domain is used if it is not set, see `openfunction.io/default-domain`.
+	//
+	// +optional
+	DomainRef *DomainReference `json:"domainRef,omitempty"`
+	// AdditionalDomainRefs references the other Domains through which the function is also accessed.
+	//
+	// +optional
+	AdditionalDomainRefs []DomainReference `json:"additionalDomainRefs,omitempty"`
+}
+
+// DomainReference references a Domain.
+type DomainReference struct {
+	// Name of the Domain.
+	Name string `json:"name"`
+	// Namespace of the Domain, it can be omitted if the name of the Domain is unique in the cluster.
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// FunctionAddress is the url of the function on a Domain.
+type FunctionAddress struct {
+	// Domain is the Domain in the form of {namespace}/{name}.
+	Domain string `json:"domain"`
+	// URL is the url of the function on the Domain.
+	URL string `json:"url"`
 }
 
 // FunctionSpec defines the desired state of Function
@@ -164,6 +291,18 @@ type FunctionSpec struct {
 	//
 	// +optional
 	Service *ServiceImpl `json:"service,omitempty"`
+	// RollbackTo is the revision recorded in `status.revisions` that the function should run.
+	// The serving of this revision will be re-created without a rebuild.
+	// As long as it is set, changes of `build` and `serving` will not be rolled out,
+	// remove it to go back to the latest spec.
+	//
+	// +optional
+	RollbackTo *int64 `json:"rollbackTo,omitempty"`
+	// RevisionHistoryLimit is the number of successful revisions to retain for rollback, default is 10.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
 }
 
 type Condition struct {
@@ -172,16 +311,119 @@ type Condition struct {
 	LastSuccessfulResourceRef string `json:"lastSuccessfulResourceRef,omitempty"`
 	ResourceHash              string `json:"resourceHash,omitempty"`
 	Service                   string `json:"service,omitempty"`
+	// ServicePort is the port of the service, default to 80.
+	// +optional
+	ServicePort int32 `json:"servicePort,omitempty"`
+	// ServiceHost is the host header the requests to the service must carry.
+	// +optional
+	ServiceHost string `json:"serviceHost,omitempty"`
+	// Reason is a brief CamelCase string that describes the state.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message is a human-readable message about the state.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// RolloutStatus describes the progress of shifting traffic to a new serving.
+type RolloutStatus struct {
+	// StableServing is the serving which the traffic is shifted from.
+	StableServing string `json:"stableServing,omitempty"`
+	// StableService is the service of the stable serving.
+	StableService string `json:"stableService,omitempty"`
+	// CanaryServing is the serving which the traffic is shifted to.
+	CanaryServing string `json:"canaryServing,omitempty"`
+	// CanaryService is the service of the canary serving.
+	CanaryService string `json:"canaryService,omitempty"`
+	// CanaryServicePort is the port of the service of the canary serving, default to 80.
+	// +optional
+	CanaryServicePort int32 `json:"canaryServicePort,omitempty"`
+	// SplitByServing is true when the canary serving splits the traffic with the stable serving itself,
+	// the function routes all traffic to the canary service then.
+	// +optional
+	SplitByServing bool `json:"splitByServing,omitempty"`
+	// Step is the index of the current rollout step.
+	Step int32 `json:"step"`
+	// Weight is the percentage of traffic currently routed to the canary serving.
+	Weight int32 `json:"weight"`
+	// StepStartTime is the time when the current step started.
+	StepStartTime metav1.Time `json:"stepStartTime,omitempty"`
+}
+
+// RollbackStatus records an automatic rollback caused by a failed serving.
+type RollbackStatus struct {
+	// FailedServing is the serving which failed to run.
+	FailedServing string `json:"failedServing,omitempty"`
+	// Serving is the serving which the function rolled back to.
+	Serving string `json:"serving,omitempty"`
+	// Reason is the state of the failed serving, such as `Failed` or `Timeout`.
+	Reason string `json:"reason,omitempty"`
+	// Message describes why the rollout was abandoned.
+	Message string `json:"message,omitempty"`
+	// Timestamp is the time when the rollback happened.
+	Timestamp metav1.Time `json:"timestamp,omitempty"`
+}
+
+// Revision records a serving of the function that had run successfully.
+type Revision struct {
+	// Revision is the sequence number of the revision.
+	Revision int64 `json:"revision"`
+	// Image is the function image used by the revision.
+	Image string `json:"image,omitempty"`
+	// BuilderHash is the hash of the builder spec which built the image.
+	BuilderHash string `json:"builderHash,omitempty"`
+	// Digest is the digest of the image resolved by the build, e.g. `sha256:...`.
+	// The revision runs the image pinned by the digest, even if the tag of the image is pushed again.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+	// ServingHash is the hash of the serving spec.
+	ServingHash string `json:"servingHash,omitempty"`
+	// ServingSpec is the serialized serving spec, used to re-create the serving when rolling back to this revision.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	ServingSpec runtime.RawExtension `json:"servingSpec"`
+	// Timestamp is the last time the revision became running.
+	Timestamp metav1.Time `json:"timestamp,omitempty"`
 }
 
 // FunctionStatus defines the observed state of Function
 type FunctionStatus struct {
 	Build   *Condition `json:"build,omitempty"`
 	Serving *Condition `json:"serving,omitempty"`
+	// Source holds the commit resolved from the source revision and the commit of the last successful build.
+	// +optional
+	Source *SourceStatus `json:"source,omitempty"`
+	// Image holds the image built by the last successful build, pinned by its digest,
+	// it has the form {spec.image}@sha256:{digest}. The serving runs this image instead of the mutable tag.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Rollout holds the progress of shifting traffic to a new serving, it is nil when no rollout is in progress.
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+	// LastRollback holds the last automatic rollback of the function.
+	// +optional
+	LastRollback *RollbackStatus `json:"lastRollback,omitempty"`
+	// Revisions holds the history of successful servings, ordered by revision.
+	// +optional
+	Revisions []Revision `json:"revisions,omitempty"`
 	// URL holds the url that used to access the Function.
-	// It generally has the form http://{domain-name}.{domain-namespace}:{domain-port}/{function-namespace}/{function-name}
+	// It generally has the form http(s)://{domain-name}.{domain-namespace}:{domain-port}/{function-namespace}/{function-name},
+	// it uses https if TLS is enabled for the Domain, and it is http(s)://{function-name}.{function-namespace}.{domain-host}
+	// if the Domain is in `Host` routing mode.
 	// +optional
 	URL string `json:"url,omitempty"`
+	// Addresses holds the urls of the function on all the Domains it is exposed on.
+	// +optional
+	Addresses []FunctionAddress `json:"addresses,omitempty"`
+	// Conditions describe the state of the function in a standard way.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// ObservedGeneration is the generation of the function observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -216,3 +458,27 @@ type FunctionList struct {
 func init() {
 	SchemeBuilder.Register(&Function{}, &FunctionList{})
 }
+
+// PinnedImage returns the image pinned by digest if it was built for the given image, or the given image otherwise.
+func (s *FunctionStatus) PinnedImage(image string) string {
+	if s.Image == "" {
+		return image
+	}
+
+	if strings.SplitN(s.Image, "@", 2)[0] != image {
+		return image
+	}
+
+	return s.Image
+}
+
+// GetRevision returns the revision with the given sequence number, or nil if it is not recorded.
+func (s *FunctionStatus) GetRevision(revision int64) *Revision {
+	for i := range s.Revisions {
+		if s.Revisions[i].Revision == revision {
+			return &s.Revisions[i]
+		}
+	}
+
+	return nil
+}
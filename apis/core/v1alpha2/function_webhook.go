@@ -17,25 +17,204 @@ limitations under the License.
 package v1alpha2
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 // log is for logging in this package.
 var functionlog = logf.Log.WithName("function-resource")
 
+//+kubebuilder:rbac:groups=core.openfunction.io,resources=functiondefaults,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core.openfunction.io,resources=clusterfunctiondefaults,verbs=get;list;watch
+
 func (r *Function) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	decoder, err := admission.NewDecoder(mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+
+	// The defaults are read by the API reader, so that the webhook does not depend on the namespaces watched by the manager.
+	mgr.GetWebhookServer().Register(functionDefaulterPath, &webhook.Admission{
+		Handler: &functionDefaulter{reader: mgr.GetAPIReader(), decoder: decoder},
+	})
+
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
 }
 
-// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
-// +kubebuilder:webhook:path=/mutate-core-openfunction-io-v1alpha2-function,mutating=true,failurePolicy=fail,groups=core.openfunction.io,resources=functions,verbs=create;update,versions=v1alpha2,name=mfunctions.of.io,sideEffects=None,admissionReviewVersions=v1
-var _ webhook.Defaulter = &Function{}
+const functionDefaulterPath = "/mutate-core-openfunction-io-v1alpha2-function"
+
+// +kubebuilder:webhook:path=/mutate-core-openfunction-io-v1alpha2-function,mutating=true,failurePolicy=fail,groups=core.openfunction.io,resources=functions,verbs=create,versions=v1alpha2,name=mfunctions.of.io,sideEffects=None,admissionReviewVersions=v1
+
+// functionDefaulter merges the FunctionDefaults and ClusterFunctionDefaults into the functions on creation.
+// It is a raw admission handler rather than a `webhook.Defaulter`, so that the request is rejected
+// if the defaults can not be read, instead of creating the function without them.
+// The defaults are not applied on update, so that changing the defaults does not change the existing functions.
+type functionDefaulter struct {
+	reader  client.Reader
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &functionDefaulter{}
+
+func (d *functionDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("defaults are only applied on creation")
+	}
+
+	fn := &Function{}
+	if err := d.decoder.Decode(req, fn); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	functionlog.Info("default", "name", fn.Name)
+	if err := applyDefaults(ctx, d.reader, fn); err != nil {
+		functionlog.Error(err, "Failed to apply defaults", "name", fn.Name)
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	marshalled, err := json.Marshal(fn)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshalled)
+}
+
+// Merge the FunctionDefaults in the namespace of the function and then the ClusterFunctionDefaults into the function,
+// the values set by the function take precedence. Several defaults of the same scope are applied in the order of their names.
+func applyDefaults(ctx context.Context, reader client.Reader, fn *Function) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	defaults := &FunctionDefaultsList{}
+	if err := reader.List(ctx, defaults, client.InNamespace(fn.Namespace)); err != nil {
+		return fmt.Errorf("failed to list FunctionDefaults: %w", err)
+	}
+
+	// The manager installed for a set of namespaces may not be allowed to read the cluster scoped defaults,
+	// the functions are created without them in that case.
+	clusterDefaults := &ClusterFunctionDefaultsList{}
+	if err := reader.List(ctx, clusterDefaults); err != nil {
+		if !apierrors.IsForbidden(err) {
+			return fmt.Errorf("failed to list ClusterFunctionDefaults: %w", err)
+		}
+		functionlog.V(1).Info("Not allowed to list ClusterFunctionDefaults, skip them", "name", fn.Name)
+	}
+
+	sort.Slice(defaults.Items, func(i, j int) bool {
+		return defaults.Items[i].Name < defaults.Items[j].Name
+	})
+	for _, d := range defaults.Items {
+		d.Spec.Apply(fn)
+	}
+
+	sort.Slice(clusterDefaults.Items, func(i, j int) bool {
+		return clusterDefaults.Items[i].Name < clusterDefaults.Items[j].Name
+	})
+	for _, d := range clusterDefaults.Items {
+		d.Spec.Apply(fn)
+	}
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-core-openfunction-io-v1alpha2-function,mutating=false,failurePolicy=fail,groups=core.openfunction.io,resources=functions,verbs=create;update,versions=v1alpha2,name=vfunctions.of.io,sideEffects=None,admissionReviewVersions=v1
+var _ webhook.Validator = &Function{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Function) ValidateCreate() error {
+	functionlog.Info("validate create", "name", r.Name)
+	return r.invalid(r.validate())
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Function) ValidateUpdate(old runtime.Object) error {
+	functionlog.Info("validate update", "name", r.Name)
+
+	// Do not block removing the finalizers of the objects created before the validation was introduced.
+	if r.DeletionTimestamp != nil {
+		return nil
+	}
+
+	allErrs := r.validate()
+	if o, ok := old.(*Function); ok {
+		allErrs = ratchet(allErrs, o.validate())
+	}
+
+	return r.invalid(allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Function) ValidateDelete() error {
+	return nil
+}
+
+func (r *Function) validate() field.ErrorList {
+	spec := field.NewPath("spec")
+
+	var allErrs field.ErrorList
+	if r.Spec.Image == "" {
+		allErrs = append(allErrs, field.Required(spec.Child("image"), "image must be set"))
+	}
+	allErrs = append(allErrs, validateVersion(spec.Child("version"), r.Spec.Version)...)
+	allErrs = append(allErrs, validatePort(spec.Child("port"), r.Spec.Port)...)
+
+	if build := r.Spec.Build; build != nil {
+		allErrs = append(allErrs, validateBuild(spec.Child("build"), build)...)
+	}
+
+	if serving := r.Spec.Serving; serving != nil {
+		allErrs = append(allErrs, validateRuntime(spec.Child("serving"), serving.Runtime, r.Spec.Version, serving.OpenFuncAsync, serving.Plain)...)
+	}
+
+	return allErrs
+}
+
+func (r *Function) invalid(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(GroupVersion.WithKind("Function").GroupKind(), r.Name, allErrs)
+}
+
+func validateBuild(path *field.Path, build *BuildImpl) field.ErrorList {
+	var allErrs field.ErrorList
+	if build.SrcRepo == nil || build.SrcRepo.Url == "" {
+		allErrs = append(allErrs, field.Required(path.Child("srcRepo", "url"), "the url of the source repository must be set"))
+	}
+
+	// The builder image is only used by the Shipwright engine, which is the default one.
+	if (build.Engine == nil || *build.Engine == Shipwright) && (build.Builder == nil || *build.Builder == "") {
+		allErrs = append(allErrs, field.Required(path.Child("builder"), "builder must be set when the engine is Shipwright"))
+	}
+
+	if build.Shipwright != nil && build.Shipwright.Strategy != nil {
+		strategy := build.Shipwright.Strategy
+		if strategy.Name == "" {
+			allErrs = append(allErrs, field.Required(path.Child("shipwright", "strategy", "name"), "the name of the strategy must be set"))
+		}
+		if strategy.Kind != nil && *strategy.Kind != "BuildStrategy" && *strategy.Kind != "ClusterBuildStrategy" {
+			allErrs = append(allErrs, field.NotSupported(path.Child("shipwright", "strategy", "kind"), *strategy.Kind,
+				[]string{"BuildStrategy", "ClusterBuildStrategy"}))
+		}
+	}
 
-// Default implements webhook.Defaulter so a webhook will be registered for the type
-func (r *Function) Default() {
-	functionlog.Info("default", "name", r.Name)
+	return allErrs
 }
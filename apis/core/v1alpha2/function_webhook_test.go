@@ -0,0 +1,296 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+type failingReader struct {
+	client.Reader
+}
+
+func (r failingReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return errors.New("unavailable")
+}
+
+// forbiddenClusterReader is not allowed to read the cluster scoped resources, as the manager installed for a set of namespaces.
+type forbiddenClusterReader struct {
+	client.Reader
+}
+
+func (r forbiddenClusterReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if _, ok := list.(*ClusterFunctionDefaultsList); ok {
+		return apierrors.NewForbidden(GroupVersion.WithResource("clusterfunctiondefaults").GroupResource(), "", errors.New("forbidden"))
+	}
+	return r.Reader.List(ctx, list, opts...)
+}
+
+func TestFunctionDefaulter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credentials := func(name string) FunctionDefaultsSpec {
+		return FunctionDefaultsSpec{ImageCredentials: &v1.LocalObjectReference{Name: name}}
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&ClusterFunctionDefaults{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Spec: credentials("cluster")},
+		&FunctionDefaults{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "default"}, Spec: credentials("namespace-b")},
+		&FunctionDefaults{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default"}, Spec: credentials("namespace-a")},
+		&FunctionDefaults{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "other"}, Spec: credentials("other")},
+	).Build()
+
+	tests := []struct {
+		name        string
+		reader      client.Reader
+		namespace   string
+		operation   admissionv1.Operation
+		wantAllowed bool
+		wantPatched bool
+		want        string
+	}{
+		{
+			name:        "the first defaults of the namespace take precedence",
+			reader:      reader,
+			namespace:   "default",
+			operation:   admissionv1.Create,
+			wantAllowed: true,
+			wantPatched: true,
+			want:        "namespace-a",
+		},
+		{
+			name:        "cluster defaults apply if the namespace has no defaults",
+			reader:      reader,
+			namespace:   "empty",
+			operation:   admissionv1.Create,
+			wantAllowed: true,
+			wantPatched: true,
+			want:        "cluster",
+		},
+		{
+			name:        "defaults are not applied on update",
+			reader:      reader,
+			namespace:   "default",
+			operation:   admissionv1.Update,
+			wantAllowed: true,
+		},
+		{
+			name:        "namespace defaults apply if the cluster defaults are forbidden",
+			reader:      forbiddenClusterReader{reader},
+			namespace:   "other",
+			operation:   admissionv1.Create,
+			wantAllowed: true,
+			wantPatched: true,
+			want:        "other",
+		},
+		{
+			name:        "allowed without defaults if the cluster defaults are forbidden",
+			reader:      forbiddenClusterReader{reader},
+			namespace:   "empty",
+			operation:   admissionv1.Create,
+			wantAllowed: true,
+		},
+		{
+			name:      "rejected if the defaults can not be read",
+			reader:    failingReader{},
+			namespace: "default",
+			operation: admissionv1.Create,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := &Function{
+				TypeMeta:   metav1.TypeMeta{APIVersion: GroupVersion.String(), Kind: "Function"},
+				ObjectMeta: metav1.ObjectMeta{Name: "function", Namespace: tt.namespace},
+				Spec:       FunctionSpec{Image: "function:v1"},
+			}
+			raw, err := json.Marshal(fn)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			d := &functionDefaulter{reader: tt.reader, decoder: decoder}
+			resp := d.Handle(context.Background(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: tt.operation,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+
+			if resp.Allowed != tt.wantAllowed {
+				t.Fatalf("Allowed = %v, want %v", resp.Allowed, tt.wantAllowed)
+			}
+			var got interface{}
+			for _, patch := range resp.Patches {
+				if patch.Path == "/spec/imageCredentials" {
+					got = patch.Value
+				}
+			}
+			if (got != nil) != tt.wantPatched {
+				t.Fatalf("Patches = %v, want patched %v", resp.Patches, tt.wantPatched)
+			}
+			if tt.wantPatched && !reflect.DeepEqual(got, map[string]interface{}{"name": tt.want}) {
+				t.Errorf("ImageCredentials = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFunctionValidate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	engine := func(e Engine) *Engine { return &e }
+	runtime := func(r Runtime) *Runtime { return &r }
+
+	tests := []struct {
+		name       string
+		spec       FunctionSpec
+		wantFields []string
+	}{
+		{
+			name: "valid",
+			spec: FunctionSpec{
+				Image: "function:v1",
+				Build: &BuildImpl{
+					Builder: str("builder"),
+					SrcRepo: &GitRepo{Url: "https://github.com/openfunction/samples.git"},
+				},
+				Serving: &ServingImpl{Runtime: runtime(Knative)},
+			},
+		},
+		{
+			name:       "image missing",
+			spec:       FunctionSpec{},
+			wantFields: []string{"spec.image"},
+		},
+		{
+			name: "shipwright build without builder and source",
+			spec: FunctionSpec{
+				Image: "function:v1",
+				Build: &BuildImpl{},
+			},
+			wantFields: []string{"spec.build.srcRepo.url", "spec.build.builder"},
+		},
+		{
+			name: "kaniko build without builder",
+			spec: FunctionSpec{
+				Image: "function:v1",
+				Build: &BuildImpl{
+					Engine:  engine(Kaniko),
+					SrcRepo: &GitRepo{Url: "https://github.com/openfunction/samples.git"},
+				},
+			},
+		},
+		{
+			name: "invalid strategy",
+			spec: FunctionSpec{
+				Image: "function:v1",
+				Build: &BuildImpl{
+					Builder:    str("builder"),
+					SrcRepo:    &GitRepo{Url: "https://github.com/openfunction/samples.git"},
+					Shipwright: &ShipwrightEngine{Strategy: &Strategy{Kind: str("Strategy")}},
+				},
+			},
+			wantFields: []string{"spec.build.shipwright.strategy.name", "spec.build.shipwright.strategy.kind"},
+		},
+		{
+			name: "serving without runtime",
+			spec: FunctionSpec{
+				Image:   "function:v1",
+				Serving: &ServingImpl{},
+			},
+			wantFields: []string{"spec.serving.runtime"},
+		},
+		{
+			name: "async serving without version",
+			spec: FunctionSpec{
+				Image:   "function:v1",
+				Serving: &ServingImpl{Runtime: runtime(OpenFuncAsync), OpenFuncAsync: &OpenFuncAsyncRuntime{}},
+			},
+			wantFields: []string{"spec.version"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := &Function{Spec: tt.spec}
+			assertFields(t, fn.validate(), tt.wantFields)
+		})
+	}
+}
+
+func TestFunctionValidateUpdate(t *testing.T) {
+	// The function was created before the validation was introduced.
+	old := &Function{Spec: FunctionSpec{Image: "function:v1", Build: &BuildImpl{}}}
+
+	tests := []struct {
+		name    string
+		spec    FunctionSpec
+		wantErr bool
+	}{
+		{
+			name: "existing errors are kept",
+			spec: FunctionSpec{Image: "function:v2", Build: &BuildImpl{}},
+		},
+		{
+			name:    "new error",
+			spec:    FunctionSpec{Build: &BuildImpl{}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := &Function{Spec: tt.spec}
+			if err := fn.ValidateUpdate(old); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func assertFields(t *testing.T, errs field.ErrorList, want []string) {
+	t.Helper()
+
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Field)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("errors on %v, want %v: %v", got, want, errs)
+	}
+}
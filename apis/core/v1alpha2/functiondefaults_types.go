@@ -0,0 +1,234 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The container which runs the function, it equals `FunctionContainer` of the controllers.
+const functionContainer = "function"
+
+type BuildDefaults struct {
+	// Engine is the default build engine.
+	// +optional
+	Engine *Engine `json:"engine,omitempty"`
+	// Builder is the default builder image.
+	// +optional
+	Builder *string `json:"builder,omitempty"`
+	// BuilderCredentials is the default secret to pull the builder image.
+	// +optional
+	BuilderCredentials *v1.LocalObjectReference `json:"builderCredentials,omitempty"`
+	// Shipwright holds the defaults of the Shipwright engine, such as the build strategy.
+	// +optional
+	Shipwright *ShipwrightEngine `json:"shipwright,omitempty"`
+	// Kaniko holds the defaults of the Kaniko engine.
+	// +optional
+	Kaniko *KanikoEngine `json:"kaniko,omitempty"`
+	// Timeout is the default build timeout.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+type ServingDefaults struct {
+	// Runtime is the default function runtime.
+	// +optional
+	Runtime *Runtime `json:"runtime,omitempty"`
+	// Resources are the default resources of the `function` container.
+	// +optional
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+	// Timeout is the default serving timeout.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// FunctionDefaultsSpec defines the values which are merged into the Functions when they are created,
+// the values set by the Functions take precedence.
+type FunctionDefaultsSpec struct {
+	// ImageRegistry is prepended to `spec.image` when it does not contain a registry, such as `registry.example.com/team`.
+	// +optional
+	ImageRegistry *string `json:"imageRegistry,omitempty"`
+	// ImageCredentials is the default secret to push and pull the function image.
+	// +optional
+	ImageCredentials *v1.LocalObjectReference `json:"imageCredentials,omitempty"`
+	// Build holds the defaults of `spec.build`, they only take effect when the Function is built.
+	// +optional
+	Build *BuildDefaults `json:"build,omitempty"`
+	// Serving holds the defaults of `spec.serving`, they only take effect when the Function is served.
+	// +optional
+	Serving *ServingDefaults `json:"serving,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// FunctionDefaults holds the defaults of the Functions in its namespace.
+// They take precedence over the ClusterFunctionDefaults.
+type FunctionDefaults struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FunctionDefaultsSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// FunctionDefaultsList contains a list of FunctionDefaults
+type FunctionDefaultsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FunctionDefaults `json:"items"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+
+// ClusterFunctionDefaults holds the defaults of the Functions in all namespaces.
+type ClusterFunctionDefaults struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FunctionDefaultsSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterFunctionDefaultsList contains a list of ClusterFunctionDefaults
+type ClusterFunctionDefaultsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterFunctionDefaults `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FunctionDefaults{}, &FunctionDefaultsList{}, &ClusterFunctionDefaults{}, &ClusterFunctionDefaultsList{})
+}
+
+// Apply fills the fields which are not set by the function with the defaults.
+func (d *FunctionDefaultsSpec) Apply(fn *Function) {
+	spec := &fn.Spec
+
+	if d.ImageRegistry != nil && *d.ImageRegistry != "" && spec.Image != "" && !hasRegistry(spec.Image) {
+		spec.Image = strings.TrimSuffix(*d.ImageRegistry, "/") + "/" + spec.Image
+	}
+
+	if spec.ImageCredentials == nil && d.ImageCredentials != nil {
+		spec.ImageCredentials = d.ImageCredentials.DeepCopy()
+	}
+
+	if spec.Build != nil && d.Build != nil {
+		d.Build.apply(spec.Build)
+	}
+
+	if spec.Serving != nil && d.Serving != nil {
+		d.Serving.apply(spec.Serving)
+	}
+}
+
+func (d *BuildDefaults) apply(build *BuildImpl) {
+	if build.Engine == nil && d.Engine != nil {
+		engine := *d.Engine
+		build.Engine = &engine
+	}
+
+	if build.Builder == nil && d.Builder != nil {
+		builder := *d.Builder
+		build.Builder = &builder
+	}
+
+	if build.BuilderCredentials == nil && d.BuilderCredentials != nil {
+		build.BuilderCredentials = d.BuilderCredentials.DeepCopy()
+	}
+
+	if d.Shipwright != nil {
+		if build.Shipwright == nil {
+			build.Shipwright = &ShipwrightEngine{}
+		}
+		if build.Shipwright.Strategy == nil && d.Shipwright.Strategy != nil {
+			build.Shipwright.Strategy = d.Shipwright.Strategy.DeepCopy()
+		}
+		if build.Shipwright.Timeout == nil && d.Shipwright.Timeout != nil {
+			build.Shipwright.Timeout = d.Shipwright.Timeout.DeepCopy()
+		}
+	}
+
+	if d.Kaniko != nil {
+		if build.Kaniko == nil {
+			build.Kaniko = &KanikoEngine{}
+		}
+		if build.Kaniko.Image == nil && d.Kaniko.Image != nil {
+			image := *d.Kaniko.Image
+			build.Kaniko.Image = &image
+		}
+		if build.Kaniko.GitImage == nil && d.Kaniko.GitImage != nil {
+			image := *d.Kaniko.GitImage
+			build.Kaniko.GitImage = &image
+		}
+		if build.Kaniko.Args == nil && d.Kaniko.Args != nil {
+			build.Kaniko.Args = append([]string(nil), d.Kaniko.Args...)
+		}
+	}
+
+	if build.Timeout == nil && d.Timeout != nil {
+		build.Timeout = d.Timeout.DeepCopy()
+	}
+}
+
+func (d *ServingDefaults) apply(serving *ServingImpl) {
+	if serving.Runtime == nil && d.Runtime != nil {
+		runtime := *d.Runtime
+		serving.Runtime = &runtime
+	}
+
+	if d.Resources != nil {
+		if serving.Template == nil {
+			serving.Template = &v1.PodSpec{}
+		}
+
+		var container *v1.Container
+		for i := range serving.Template.Containers {
+			if serving.Template.Containers[i].Name == functionContainer {
+				container = &serving.Template.Containers[i]
+			}
+		}
+		if container == nil {
+			serving.Template.Containers = append(serving.Template.Containers, v1.Container{Name: functionContainer})
+			container = &serving.Template.Containers[len(serving.Template.Containers)-1]
+		}
+
+		if container.Resources.Limits == nil && container.Resources.Requests == nil {
+			container.Resources = *d.Resources.DeepCopy()
+		}
+	}
+
+	if serving.Timeout == nil && d.Timeout != nil {
+		serving.Timeout = d.Timeout.DeepCopy()
+	}
+}
+
+// The first component of an image is a registry if it is a host, see https://docs.docker.com/engine/reference/commandline/tag/.
+func hasRegistry(image string) bool {
+	i := strings.Index(image, "/")
+	if i < 0 {
+		return false
+	}
+
+	host := image[:i]
+	return host == "localhost" || strings.ContainsAny(host, ".:")
+}
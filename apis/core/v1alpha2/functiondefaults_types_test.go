@@ -0,0 +1,177 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFunctionDefaultsSpecApply(t *testing.T) {
+	str := func(s string) *string { return &s }
+	engine := func(e Engine) *Engine { return &e }
+	runtime := func(r Runtime) *Runtime { return &r }
+	duration := func(d time.Duration) *metav1.Duration { return &metav1.Duration{Duration: d} }
+	resources := &v1.ResourceRequirements{
+		Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+	}
+
+	defaults := FunctionDefaultsSpec{
+		ImageRegistry:    str("registry.example.com/team/"),
+		ImageCredentials: &v1.LocalObjectReference{Name: "default-push-secret"},
+		Build: &BuildDefaults{
+			Engine:             engine(Kaniko),
+			Builder:            str("default-builder"),
+			BuilderCredentials: &v1.LocalObjectReference{Name: "default-pull-secret"},
+			Kaniko:             &KanikoEngine{Image: str("default-kaniko"), Args: []string{"--cache"}},
+			Timeout:            duration(time.Hour),
+		},
+		Serving: &ServingDefaults{
+			Runtime:   runtime(Plain),
+			Resources: resources,
+			Timeout:   duration(time.Minute),
+		},
+	}
+
+	tests := []struct {
+		name string
+		spec FunctionSpec
+		want FunctionSpec
+	}{
+		{
+			name: "defaults fill the unset fields",
+			spec: FunctionSpec{
+				Image:   "function:v1",
+				Build:   &BuildImpl{},
+				Serving: &ServingImpl{},
+			},
+			want: FunctionSpec{
+				Image:            "registry.example.com/team/function:v1",
+				ImageCredentials: &v1.LocalObjectReference{Name: "default-push-secret"},
+				Build: &BuildImpl{
+					Engine:             engine(Kaniko),
+					Builder:            str("default-builder"),
+					BuilderCredentials: &v1.LocalObjectReference{Name: "default-pull-secret"},
+					Kaniko:             &KanikoEngine{Image: str("default-kaniko"), Args: []string{"--cache"}},
+					Timeout:            duration(time.Hour),
+				},
+				Serving: &ServingImpl{
+					Runtime: runtime(Plain),
+					Template: &v1.PodSpec{
+						Containers: []v1.Container{{Name: functionContainer, Resources: *resources}},
+					},
+					Timeout: duration(time.Minute),
+				},
+			},
+		},
+		{
+			name: "values of the function take precedence",
+			spec: FunctionSpec{
+				Image:            "docker.io/user/function:v1",
+				ImageCredentials: &v1.LocalObjectReference{Name: "push-secret"},
+				Build: &BuildImpl{
+					Engine:             engine(Shipwright),
+					Builder:            str("builder"),
+					BuilderCredentials: &v1.LocalObjectReference{Name: "pull-secret"},
+					Kaniko:             &KanikoEngine{Image: str("kaniko"), Args: []string{}},
+					Timeout:            duration(time.Second),
+				},
+				Serving: &ServingImpl{
+					Runtime: runtime(Knative),
+					Template: &v1.PodSpec{
+						Containers: []v1.Container{{
+							Name: functionContainer,
+							Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("64Mi")},
+							},
+						}},
+					},
+					Timeout: duration(time.Second),
+				},
+			},
+			want: FunctionSpec{
+				Image:            "docker.io/user/function:v1",
+				ImageCredentials: &v1.LocalObjectReference{Name: "push-secret"},
+				Build: &BuildImpl{
+					Engine:             engine(Shipwright),
+					Builder:            str("builder"),
+					BuilderCredentials: &v1.LocalObjectReference{Name: "pull-secret"},
+					Kaniko:             &KanikoEngine{Image: str("kaniko"), Args: []string{}},
+					Timeout:            duration(time.Second),
+				},
+				Serving: &ServingImpl{
+					Runtime: runtime(Knative),
+					Template: &v1.PodSpec{
+						Containers: []v1.Container{{
+							Name: functionContainer,
+							Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("64Mi")},
+							},
+						}},
+					},
+					Timeout: duration(time.Second),
+				},
+			},
+		},
+		{
+			name: "build and serving defaults are ignored if the function is not built or served",
+			spec: FunctionSpec{
+				Image: "localhost:5000/function:v1",
+			},
+			want: FunctionSpec{
+				Image:            "localhost:5000/function:v1",
+				ImageCredentials: &v1.LocalObjectReference{Name: "default-push-secret"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := &Function{Spec: tt.spec}
+			defaults.Apply(fn)
+			if !reflect.DeepEqual(fn.Spec, tt.want) {
+				t.Errorf("Apply() = %+v, want %+v", fn.Spec, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasRegistry(t *testing.T) {
+	tests := []struct {
+		image string
+		want  bool
+	}{
+		{image: "function", want: false},
+		{image: "user/function:v1", want: false},
+		{image: "docker.io/user/function", want: true},
+		{image: "localhost/function", want: true},
+		{image: "registry:5000/function", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := hasRegistry(tt.image); got != tt.want {
+				t.Errorf("hasRegistry(%q) = %v, want %v", tt.image, got, tt.want)
+			}
+		})
+	}
+}
@@ -111,6 +111,66 @@ type OpenFuncAsyncRuntime struct {
 	Keda *Keda `json:"keda,omitempty"`
 }
 
+// PlainRuntime runs the function with a Deployment and a Service, without Knative.
+type PlainRuntime struct {
+	// Replicas is the number of pods when autoscaling is not enabled, default to 1.
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Autoscaling creates a HorizontalPodAutoscaler to scale the function based on cpu utilization.
+	// +optional
+	Autoscaling *PlainAutoscaling `json:"autoscaling,omitempty"`
+}
+
+type PlainAutoscaling struct {
+	// MinReplicas is the lower limit of the number of pods, default to 1.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the upper limit of the number of pods.
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+	// TargetCPUUtilizationPercentage is the target average cpu utilization of the pods, default to 80.
+	// +optional
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+}
+
+// KedaHTTPRuntime runs the function with a Deployment scaled by the KEDA HTTP add-on,
+// the requests to the function go through the interceptor of the add-on, so the function can be scaled to zero.
+type KedaHTTPRuntime struct {
+	// TargetPendingRequests is the number of pending requests per replica above which the function is scaled out, default to 100.
+	// +optional
+	TargetPendingRequests *int32 `json:"targetPendingRequests,omitempty"`
+	// MinReplicas is the lower limit of the number of pods, default to 0.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the upper limit of the number of pods, default to 100.
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+	// Interceptor is the proxy service of the interceptor of the KEDA HTTP add-on,
+	// default to the service `keda-add-ons-http-interceptor-proxy` in the namespace `keda` on port 8080.
+	// +optional
+	Interceptor *KedaHTTPInterceptor `json:"interceptor,omitempty"`
+}
+
+type KedaHTTPInterceptor struct {
+	// Name of the interceptor proxy service.
+	Name string `json:"name"`
+	// Namespace of the interceptor proxy service.
+	Namespace string `json:"namespace"`
+	// Port of the interceptor proxy service.
+	Port int32 `json:"port"`
+}
+
+// ServingTraffic defines how the serving splits its traffic with a stable serving during a rollout.
+type ServingTraffic struct {
+	// StableServing is the serving which receives the traffic not routed to this serving.
+	StableServing string `json:"stableServing"`
+	// CanaryWeight is the percentage of traffic routed to this serving.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	CanaryWeight int32 `json:"canaryWeight"`
+}
+
 // ServingSpec defines the desired state of Serving
 type ServingSpec struct {
 	// Function version in format like v1.0.0
@@ -133,6 +193,12 @@ type ServingSpec struct {
 	// Parameters of OpenFuncAsync runtime.
 	// +optional
 	OpenFuncAsync *OpenFuncAsyncRuntime `json:"openFuncAsync,omitempty"`
+	// Parameters of Plain runtime.
+	// +optional
+	Plain *PlainRuntime `json:"plain,omitempty"`
+	// Parameters of KedaHTTP runtime.
+	// +optional
+	KedaHTTP *KedaHTTPRuntime `json:"kedaHTTP,omitempty"`
 	// Template describes the pods that will be created.
 	// The container named `function` is the container which is used to run the image built by the builder.
 	// If it is not set, the controller will automatically add one.
@@ -142,6 +208,12 @@ type ServingSpec struct {
 	//
 	// +optional
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Traffic splits the traffic between this serving and a stable serving, only take effect with Knative runtime.
+	// It is set by the function controller during a rollout and is not part of the serving hash.
+	// All traffic is routed to this serving if it is not set.
+	//
+	// +optional
+	Traffic *ServingTraffic `json:"traffic,omitempty" hash:"ignore"`
 }
 
 // ServingStatus defines the observed state of Serving
@@ -153,6 +225,29 @@ type ServingStatus struct {
 	// Service holds the service name used to access the serving.
 	// +optional
 	Service string `json:"url,omitempty"`
+	// ServicePort is the port of the service, default to 80.
+	// +optional
+	ServicePort int32 `json:"servicePort,omitempty"`
+	// ServiceHost is the host header the requests to the service must carry,
+	// it is set when the service routes the requests by host, such as the KEDA HTTP interceptor.
+	// +optional
+	ServiceHost string `json:"serviceHost,omitempty"`
+	// StartTime is the time the serving started, the timeout of the serving is counted from it.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Conditions describe the state of the serving in a standard way.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// ObservedGeneration is the generation of the serving observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Traffic is the traffic split which has been applied to the serving.
+	// +optional
+	Traffic *ServingTraffic `json:"traffic,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -187,3 +282,20 @@ func init() {
 func (s *ServingStatus) IsStarting() bool {
 	return s.State == "" || s.State == Starting
 }
+
+func (s *ServingStatus) IsFailed() bool {
+	return s.State == Failed || s.State == Timeout || s.State == UnknownRuntime
+}
+
+// HashInclude keeps the unset fields added after the first release out of the hash of the spec,
+// so that upgrading the controller does not recreate the existing servings.
+func (s ServingSpec) HashInclude(field string, v interface{}) (bool, error) {
+	switch field {
+	case "Plain":
+		return s.Plain != nil, nil
+	case "KedaHTTP":
+		return s.KedaHTTP != nil, nil
+	default:
+		return true, nil
+	}
+}
@@ -17,11 +17,21 @@ limitations under the License.
 package v1alpha2
 
 import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
+// The version is a part of the names of the workloads after the dots are removed.
+var versionRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?$`)
+
 // log is for logging in this package.
 var servinglog = logf.Log.WithName("serving-resource")
 
@@ -39,3 +49,148 @@ var _ webhook.Defaulter = &Serving{}
 func (r *Serving) Default() {
 	servinglog.Info("default", "name", r.Name)
 }
+
+// +kubebuilder:webhook:path=/validate-core-openfunction-io-v1alpha2-serving,mutating=false,failurePolicy=fail,groups=core.openfunction.io,resources=servings,verbs=create;update,versions=v1alpha2,name=vservings.of.io,sideEffects=None,admissionReviewVersions=v1
+var _ webhook.Validator = &Serving{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Serving) ValidateCreate() error {
+	servinglog.Info("validate create", "name", r.Name)
+	return r.invalid(r.validate())
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Serving) ValidateUpdate(old runtime.Object) error {
+	servinglog.Info("validate update", "name", r.Name)
+
+	// Do not block removing the finalizers of the objects created before the validation was introduced.
+	if r.DeletionTimestamp != nil {
+		return nil
+	}
+
+	allErrs := r.validate()
+	if o, ok := old.(*Serving); ok {
+		allErrs = ratchet(allErrs, o.validate())
+	}
+
+	return r.invalid(allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Serving) ValidateDelete() error {
+	return nil
+}
+
+func (r *Serving) validate() field.ErrorList {
+	spec := field.NewPath("spec")
+
+	var allErrs field.ErrorList
+	if r.Spec.Image == "" {
+		allErrs = append(allErrs, field.Required(spec.Child("image"), "image must be set"))
+	}
+	allErrs = append(allErrs, validateVersion(spec.Child("version"), r.Spec.Version)...)
+	allErrs = append(allErrs, validatePort(spec.Child("port"), r.Spec.Port)...)
+	allErrs = append(allErrs, validateRuntime(spec, r.Spec.Runtime, r.Spec.Version, r.Spec.OpenFuncAsync, r.Spec.Plain)...)
+
+	return allErrs
+}
+
+func (r *Serving) invalid(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(GroupVersion.WithKind("Serving").GroupKind(), r.Name, allErrs)
+}
+
+// ratchet drops the errors which the old object already has, so that the objects created before the validation
+// was introduced can still be updated, as long as the update does not make them more invalid.
+func ratchet(allErrs, oldErrs field.ErrorList) field.ErrorList {
+	var errs field.ErrorList
+	for _, err := range allErrs {
+		existing := false
+		for _, oldErr := range oldErrs {
+			if err.Type == oldErr.Type && err.Field == oldErr.Field && reflect.DeepEqual(err.BadValue, oldErr.BadValue) {
+				existing = true
+				break
+			}
+		}
+
+		if !existing {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func validateVersion(path *field.Path, version *string) field.ErrorList {
+	if version == nil || versionRegexp.MatchString(*version) {
+		return nil
+	}
+
+	return field.ErrorList{field.Invalid(path, *version,
+		"version must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character")}
+}
+
+func validatePort(path *field.Path, port *int32) field.ErrorList {
+	if port == nil || (*port > 0 && *port <= 65535) {
+		return nil
+	}
+
+	return field.ErrorList{field.Invalid(path, *port, "port must be between 1 and 65535")}
+}
+
+// validateRuntime validates the parameters of the runtime, path is the path of the serving spec.
+func validateRuntime(path *field.Path, rt *Runtime, version *string, async *OpenFuncAsyncRuntime, plain *PlainRuntime) field.ErrorList {
+	var allErrs field.ErrorList
+	if rt == nil {
+		return append(allErrs, field.Required(path.Child("runtime"), "runtime must be set"))
+	}
+
+	switch *rt {
+	case OpenFuncAsync:
+		if version == nil {
+			allErrs = append(allErrs, field.Required(path.Root().Child("version"), "version must be set when runtime is OpenFuncAsync"))
+		}
+		if async == nil {
+			allErrs = append(allErrs, field.Required(path.Child("openFuncAsync"), "openFuncAsync must be set when runtime is OpenFuncAsync"))
+			break
+		}
+		if async.Dapr != nil {
+			allErrs = append(allErrs, validateDaprIO(path.Child("openFuncAsync", "dapr", "inputs"), async.Dapr.Inputs, async.Dapr)...)
+			allErrs = append(allErrs, validateDaprIO(path.Child("openFuncAsync", "dapr", "outputs"), async.Dapr.Outputs, async.Dapr)...)
+		}
+	case Plain:
+		if plain != nil && plain.Autoscaling != nil && plain.Autoscaling.MinReplicas != nil &&
+			*plain.Autoscaling.MinReplicas > plain.Autoscaling.MaxReplicas {
+			allErrs = append(allErrs, field.Invalid(path.Child("plain", "autoscaling", "minReplicas"), *plain.Autoscaling.MinReplicas,
+				"minReplicas must not be greater than maxReplicas"))
+		}
+	}
+
+	return allErrs
+}
+
+// The inputs and outputs must reference the components defined in `dapr.components`.
+func validateDaprIO(path *field.Path, ios []*DaprIO, dapr *Dapr) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, io := range ios {
+		if io == nil {
+			continue
+		}
+
+		component, ok := dapr.Components[io.Component]
+		if !ok || component == nil {
+			allErrs = append(allErrs, field.NotFound(path.Index(i).Child("component"), io.Component))
+			continue
+		}
+
+		// The type of the input or output is the type of the component, such as `pubsub.kafka`.
+		if strings.HasPrefix(component.Type, "pubsub.") && io.Topic == "" {
+			allErrs = append(allErrs, field.Required(path.Index(i).Child("topic"), "topic must be set when the component is a pubsub"))
+		}
+	}
+
+	return allErrs
+}
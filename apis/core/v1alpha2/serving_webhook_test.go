@@ -0,0 +1,163 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"testing"
+
+	componentsv1alpha1 "github.com/dapr/dapr/pkg/apis/components/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestServingValidate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	port := func(p int32) *int32 { return &p }
+	runtime := func(r Runtime) *Runtime { return &r }
+	dapr := func(topic string) *Dapr {
+		return &Dapr{
+			Components: map[string]*componentsv1alpha1.ComponentSpec{
+				"kafka": {Type: "pubsub.kafka"},
+				"cron":  {Type: "bindings.cron"},
+			},
+			Inputs:  []*DaprIO{{Name: "cron", Component: "cron"}},
+			Outputs: []*DaprIO{{Name: "kafka", Component: "kafka", Topic: topic}},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		spec       ServingSpec
+		wantFields []string
+	}{
+		{
+			name: "valid knative",
+			spec: ServingSpec{Image: "function:v1", Version: str("v1.0.0"), Port: port(8080), Runtime: runtime(Knative)},
+		},
+		{
+			name: "valid async",
+			spec: ServingSpec{
+				Image:         "function:v1",
+				Version:       str("v1"),
+				Runtime:       runtime(OpenFuncAsync),
+				OpenFuncAsync: &OpenFuncAsyncRuntime{Dapr: dapr("sample")},
+			},
+		},
+		{
+			name:       "image, runtime missing",
+			spec:       ServingSpec{},
+			wantFields: []string{"spec.image", "spec.runtime"},
+		},
+		{
+			name:       "invalid version and port",
+			spec:       ServingSpec{Image: "function:v1", Version: str("V1_0"), Port: port(0), Runtime: runtime(Knative)},
+			wantFields: []string{"spec.version", "spec.port"},
+		},
+		{
+			name:       "async without version and parameters",
+			spec:       ServingSpec{Image: "function:v1", Runtime: runtime(OpenFuncAsync)},
+			wantFields: []string{"spec.version", "spec.openFuncAsync"},
+		},
+		{
+			name: "async output without topic",
+			spec: ServingSpec{
+				Image:         "function:v1",
+				Version:       str("v1"),
+				Runtime:       runtime(OpenFuncAsync),
+				OpenFuncAsync: &OpenFuncAsyncRuntime{Dapr: dapr("")},
+			},
+			wantFields: []string{"spec.openFuncAsync.dapr.outputs[0].topic"},
+		},
+		{
+			name: "async input of unknown component",
+			spec: ServingSpec{
+				Image:   "function:v1",
+				Version: str("v1"),
+				Runtime: runtime(OpenFuncAsync),
+				OpenFuncAsync: &OpenFuncAsyncRuntime{Dapr: &Dapr{
+					Inputs: []*DaprIO{{Name: "cron", Component: "cron"}},
+				}},
+			},
+			wantFields: []string{"spec.openFuncAsync.dapr.inputs[0].component"},
+		},
+		{
+			name: "plain min replicas greater than max",
+			spec: ServingSpec{
+				Image:   "function:v1",
+				Runtime: runtime(Plain),
+				Plain:   &PlainRuntime{Autoscaling: &PlainAutoscaling{MinReplicas: port(3), MaxReplicas: 2}},
+			},
+			wantFields: []string{"spec.plain.autoscaling.minReplicas"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Serving{Spec: tt.spec}
+			assertFields(t, s.validate(), tt.wantFields)
+		})
+	}
+}
+
+func TestServingValidateUpdate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	port := func(p int32) *int32 { return &p }
+	runtime := func(r Runtime) *Runtime { return &r }
+
+	// The serving was created before the validation was introduced.
+	old := &Serving{Spec: ServingSpec{Image: "function:v1", Version: str("V1_0"), Runtime: runtime(Knative)}}
+
+	tests := []struct {
+		name    string
+		spec    ServingSpec
+		deleted bool
+		wantErr bool
+	}{
+		{
+			name: "existing errors are kept",
+			spec: ServingSpec{Image: "function:v2", Version: str("V1_0"), Runtime: runtime(Knative)},
+		},
+		{
+			name:    "changed invalid field",
+			spec:    ServingSpec{Image: "function:v2", Version: str("V2_0"), Runtime: runtime(Knative)},
+			wantErr: true,
+		},
+		{
+			name:    "new error",
+			spec:    ServingSpec{Image: "function:v2", Version: str("V1_0"), Port: port(-1), Runtime: runtime(Knative)},
+			wantErr: true,
+		},
+		{
+			name:    "deleting",
+			spec:    ServingSpec{Port: port(-1)},
+			deleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Serving{Spec: tt.spec}
+			if tt.deleted {
+				now := metav1.Now()
+				s.DeletionTimestamp = &now
+			}
+
+			if err := s.ValidateUpdate(old); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
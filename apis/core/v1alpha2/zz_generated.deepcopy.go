@@ -1,3 +1,4 @@
+//go:build !ignore_autogenerated
 // +build !ignore_autogenerated
 
 /*
@@ -23,14 +24,84 @@ package v1alpha2
 import (
 	componentsv1alpha1 "github.com/dapr/dapr/pkg/apis/components/v1alpha1"
 	"github.com/kedacore/keda/v2/api/v1alpha1"
-	v1 "k8s.io/api/core/v1"
+	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoRebuild) DeepCopyInto(out *AutoRebuild) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoRebuild.
+func (in *AutoRebuild) DeepCopy() *AutoRebuild {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoRebuild)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildDefaults) DeepCopyInto(out *BuildDefaults) {
+	*out = *in
+	if in.Engine != nil {
+		in, out := &in.Engine, &out.Engine
+		*out = new(Engine)
+		**out = **in
+	}
+	if in.Builder != nil {
+		in, out := &in.Builder, &out.Builder
+		*out = new(string)
+		**out = **in
+	}
+	if in.BuilderCredentials != nil {
+		in, out := &in.BuilderCredentials, &out.BuilderCredentials
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Shipwright != nil {
+		in, out := &in.Shipwright, &out.Shipwright
+		*out = new(ShipwrightEngine)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kaniko != nil {
+		in, out := &in.Kaniko, &out.Kaniko
+		*out = new(KanikoEngine)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildDefaults.
+func (in *BuildDefaults) DeepCopy() *BuildDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildImpl) DeepCopyInto(out *BuildImpl) {
 	*out = *in
+	if in.Engine != nil {
+		in, out := &in.Engine, &out.Engine
+		*out = new(Engine)
+		**out = **in
+	}
 	if in.Builder != nil {
 		in, out := &in.Builder, &out.Builder
 		*out = new(string)
@@ -46,6 +117,11 @@ func (in *BuildImpl) DeepCopyInto(out *BuildImpl) {
 		*out = new(ShipwrightEngine)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Kaniko != nil {
+		in, out := &in.Kaniko, &out.Kaniko
+		*out = new(KanikoEngine)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Params != nil {
 		in, out := &in.Params, &out.Params
 		*out = make(map[string]string, len(*in))
@@ -173,11 +249,21 @@ func (in *BuilderSpec) DeepCopyInto(out *BuilderSpec) {
 		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
+	if in.Engine != nil {
+		in, out := &in.Engine, &out.Engine
+		*out = new(Engine)
+		**out = **in
+	}
 	if in.Shipwright != nil {
 		in, out := &in.Shipwright, &out.Shipwright
 		*out = new(ShipwrightEngine)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Kaniko != nil {
+		in, out := &in.Kaniko, &out.Kaniko
+		*out = new(KanikoEngine)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SrcRepo != nil {
 		in, out := &in.SrcRepo, &out.SrcRepo
 		*out = new(GitRepo)
@@ -225,6 +311,21 @@ func (in *BuilderStatus) DeepCopyInto(out *BuilderStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuilderStatus.
@@ -237,6 +338,64 @@ func (in *BuilderStatus) DeepCopy() *BuilderStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterFunctionDefaults) DeepCopyInto(out *ClusterFunctionDefaults) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterFunctionDefaults.
+func (in *ClusterFunctionDefaults) DeepCopy() *ClusterFunctionDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterFunctionDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterFunctionDefaults) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterFunctionDefaultsList) DeepCopyInto(out *ClusterFunctionDefaultsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterFunctionDefaults, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterFunctionDefaultsList.
+func (in *ClusterFunctionDefaultsList) DeepCopy() *ClusterFunctionDefaultsList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterFunctionDefaultsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterFunctionDefaultsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Condition) DeepCopyInto(out *Condition) {
 	*out = *in
@@ -339,7 +498,7 @@ func (in *Domain) DeepCopyInto(out *Domain) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Domain.
@@ -392,10 +551,30 @@ func (in *DomainList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainReference) DeepCopyInto(out *DomainReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainReference.
+func (in *DomainReference) DeepCopy() *DomainReference {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DomainSpec) DeepCopyInto(out *DomainSpec) {
 	*out = *in
 	in.Ingress.DeepCopyInto(&out.Ingress)
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(GatewayConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainSpec.
@@ -411,6 +590,18 @@ func (in *DomainSpec) DeepCopy() *DomainSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DomainStatus) DeepCopyInto(out *DomainStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Functions != nil {
+		in, out := &in.Functions, &out.Functions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainStatus.
@@ -450,6 +641,114 @@ func (in *Function) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FunctionAddress) DeepCopyInto(out *FunctionAddress) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FunctionAddress.
+func (in *FunctionAddress) DeepCopy() *FunctionAddress {
+	if in == nil {
+		return nil
+	}
+	out := new(FunctionAddress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FunctionDefaults) DeepCopyInto(out *FunctionDefaults) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FunctionDefaults.
+func (in *FunctionDefaults) DeepCopy() *FunctionDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(FunctionDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FunctionDefaults) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FunctionDefaultsList) DeepCopyInto(out *FunctionDefaultsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FunctionDefaults, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FunctionDefaultsList.
+func (in *FunctionDefaultsList) DeepCopy() *FunctionDefaultsList {
+	if in == nil {
+		return nil
+	}
+	out := new(FunctionDefaultsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FunctionDefaultsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FunctionDefaultsSpec) DeepCopyInto(out *FunctionDefaultsSpec) {
+	*out = *in
+	if in.ImageRegistry != nil {
+		in, out := &in.ImageRegistry, &out.ImageRegistry
+		*out = new(string)
+		**out = **in
+	}
+	if in.ImageCredentials != nil {
+		in, out := &in.ImageCredentials, &out.ImageCredentials
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Build != nil {
+		in, out := &in.Build, &out.Build
+		*out = new(BuildDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Serving != nil {
+		in, out := &in.Serving, &out.Serving
+		*out = new(ServingDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FunctionDefaultsSpec.
+func (in *FunctionDefaultsSpec) DeepCopy() *FunctionDefaultsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FunctionDefaultsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FunctionList) DeepCopyInto(out *FunctionList) {
 	*out = *in
@@ -515,6 +814,16 @@ func (in *FunctionSpec) DeepCopyInto(out *FunctionSpec) {
 		*out = new(ServiceImpl)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RollbackTo != nil {
+		in, out := &in.RollbackTo, &out.RollbackTo
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FunctionSpec.
@@ -540,6 +849,40 @@ func (in *FunctionStatus) DeepCopyInto(out *FunctionStatus) {
 		*out = new(Condition)
 		**out = **in
 	}
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(SourceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastRollback != nil {
+		in, out := &in.LastRollback, &out.LastRollback
+		*out = new(RollbackStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Revisions != nil {
+		in, out := &in.Revisions, &out.Revisions
+		*out = make([]Revision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]FunctionAddress, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FunctionStatus.
@@ -552,6 +895,22 @@ func (in *FunctionStatus) DeepCopy() *FunctionStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayConfig) DeepCopyInto(out *GatewayConfig) {
+	*out = *in
+	out.Service = in.Service
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayConfig.
+func (in *GatewayConfig) DeepCopy() *GatewayConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitRepo) DeepCopyInto(out *GitRepo) {
 	*out = *in
@@ -570,6 +929,11 @@ func (in *GitRepo) DeepCopyInto(out *GitRepo) {
 		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
+	if in.AutoRebuild != nil {
+		in, out := &in.AutoRebuild, &out.AutoRebuild
+		*out = new(AutoRebuild)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRepo.
@@ -593,6 +957,11 @@ func (in *IngressConfig) DeepCopyInto(out *IngressConfig) {
 		}
 	}
 	out.Service = in.Service
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(IngressTLS)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressConfig.
@@ -620,6 +989,76 @@ func (in *IngressControllerService) DeepCopy() *IngressControllerService {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressTLS) DeepCopyInto(out *IngressTLS) {
+	*out = *in
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Issuer != nil {
+		in, out := &in.Issuer, &out.Issuer
+		*out = new(IssuerReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressTLS.
+func (in *IngressTLS) DeepCopy() *IngressTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuerReference) DeepCopyInto(out *IssuerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuerReference.
+func (in *IssuerReference) DeepCopy() *IssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KanikoEngine) DeepCopyInto(out *KanikoEngine) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.GitImage != nil {
+		in, out := &in.GitImage, &out.GitImage
+		*out = new(string)
+		**out = **in
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KanikoEngine.
+func (in *KanikoEngine) DeepCopy() *KanikoEngine {
+	if in == nil {
+		return nil
+	}
+	out := new(KanikoEngine)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Keda) DeepCopyInto(out *Keda) {
 	*out = *in
@@ -645,6 +1084,56 @@ func (in *Keda) DeepCopy() *Keda {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KedaHTTPInterceptor) DeepCopyInto(out *KedaHTTPInterceptor) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KedaHTTPInterceptor.
+func (in *KedaHTTPInterceptor) DeepCopy() *KedaHTTPInterceptor {
+	if in == nil {
+		return nil
+	}
+	out := new(KedaHTTPInterceptor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KedaHTTPRuntime) DeepCopyInto(out *KedaHTTPRuntime) {
+	*out = *in
+	if in.TargetPendingRequests != nil {
+		in, out := &in.TargetPendingRequests, &out.TargetPendingRequests
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Interceptor != nil {
+		in, out := &in.Interceptor, &out.Interceptor
+		*out = new(KedaHTTPInterceptor)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KedaHTTPRuntime.
+func (in *KedaHTTPRuntime) DeepCopy() *KedaHTTPRuntime {
+	if in == nil {
+		return nil
+	}
+	out := new(KedaHTTPRuntime)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KedaScaledJob) DeepCopyInto(out *KedaScaledJob) {
 	*out = *in
@@ -765,6 +1254,147 @@ func (in *OpenFuncAsyncRuntime) DeepCopy() *OpenFuncAsyncRuntime {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlainAutoscaling) DeepCopyInto(out *PlainAutoscaling) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlainAutoscaling.
+func (in *PlainAutoscaling) DeepCopy() *PlainAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(PlainAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlainRuntime) DeepCopyInto(out *PlainRuntime) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(PlainAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlainRuntime.
+func (in *PlainRuntime) DeepCopy() *PlainRuntime {
+	if in == nil {
+		return nil
+	}
+	out := new(PlainRuntime)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Revision) DeepCopyInto(out *Revision) {
+	*out = *in
+	in.ServingSpec.DeepCopyInto(&out.ServingSpec)
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Revision.
+func (in *Revision) DeepCopy() *Revision {
+	if in == nil {
+		return nil
+	}
+	out := new(Revision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollbackStatus) DeepCopyInto(out *RollbackStatus) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollbackStatus.
+func (in *RollbackStatus) DeepCopy() *RollbackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RollbackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutPolicy) DeepCopyInto(out *RolloutPolicy) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]RolloutStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutPolicy.
+func (in *RolloutPolicy) DeepCopy() *RolloutPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
+	*out = *in
+	in.StepStartTime.DeepCopyInto(&out.StepStartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
+func (in *RolloutStatus) DeepCopy() *RolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStep) DeepCopyInto(out *RolloutStep) {
+	*out = *in
+	if in.Pause != nil {
+		in, out := &in.Pause, &out.Pause
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStep.
+func (in *RolloutStep) DeepCopy() *RolloutStep {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceImpl) DeepCopyInto(out *ServiceImpl) {
 	*out = *in
@@ -775,6 +1405,16 @@ func (in *ServiceImpl) DeepCopyInto(out *ServiceImpl) {
 			(*out)[key] = val
 		}
 	}
+	if in.DomainRef != nil {
+		in, out := &in.DomainRef, &out.DomainRef
+		*out = new(DomainReference)
+		**out = **in
+	}
+	if in.AdditionalDomainRefs != nil {
+		in, out := &in.AdditionalDomainRefs, &out.AdditionalDomainRefs
+		*out = make([]DomainReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceImpl.
@@ -814,6 +1454,36 @@ func (in *Serving) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServingDefaults) DeepCopyInto(out *ServingDefaults) {
+	*out = *in
+	if in.Runtime != nil {
+		in, out := &in.Runtime, &out.Runtime
+		*out = new(Runtime)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingDefaults.
+func (in *ServingDefaults) DeepCopy() *ServingDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(ServingDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServingImpl) DeepCopyInto(out *ServingImpl) {
 	*out = *in
@@ -834,6 +1504,16 @@ func (in *ServingImpl) DeepCopyInto(out *ServingImpl) {
 		*out = new(OpenFuncAsyncRuntime)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Plain != nil {
+		in, out := &in.Plain, &out.Plain
+		*out = new(PlainRuntime)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KedaHTTP != nil {
+		in, out := &in.KedaHTTP, &out.KedaHTTP
+		*out = new(KedaHTTPRuntime)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Template != nil {
 		in, out := &in.Template, &out.Template
 		*out = new(v1.PodSpec)
@@ -844,6 +1524,11 @@ func (in *ServingImpl) DeepCopyInto(out *ServingImpl) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingImpl.
@@ -923,6 +1608,16 @@ func (in *ServingSpec) DeepCopyInto(out *ServingSpec) {
 		*out = new(OpenFuncAsyncRuntime)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Plain != nil {
+		in, out := &in.Plain, &out.Plain
+		*out = new(PlainRuntime)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KedaHTTP != nil {
+		in, out := &in.KedaHTTP, &out.KedaHTTP
+		*out = new(KedaHTTPRuntime)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Template != nil {
 		in, out := &in.Template, &out.Template
 		*out = new(v1.PodSpec)
@@ -933,6 +1628,11 @@ func (in *ServingSpec) DeepCopyInto(out *ServingSpec) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.Traffic != nil {
+		in, out := &in.Traffic, &out.Traffic
+		*out = new(ServingTraffic)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingSpec.
@@ -955,6 +1655,22 @@ func (in *ServingStatus) DeepCopyInto(out *ServingStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Traffic != nil {
+		in, out := &in.Traffic, &out.Traffic
+		*out = new(ServingTraffic)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingStatus.
@@ -967,6 +1683,21 @@ func (in *ServingStatus) DeepCopy() *ServingStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServingTraffic) DeepCopyInto(out *ServingTraffic) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingTraffic.
+func (in *ServingTraffic) DeepCopy() *ServingTraffic {
+	if in == nil {
+		return nil
+	}
+	out := new(ServingTraffic)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ShipwrightEngine) DeepCopyInto(out *ShipwrightEngine) {
 	*out = *in
@@ -992,6 +1723,25 @@ func (in *ShipwrightEngine) DeepCopy() *ShipwrightEngine {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SourceStatus) DeepCopyInto(out *SourceStatus) {
+	*out = *in
+	if in.LastResolveTime != nil {
+		in, out := &in.LastResolveTime, &out.LastResolveTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SourceStatus.
+func (in *SourceStatus) DeepCopy() *SourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Strategy) DeepCopyInto(out *Strategy) {
 	*out = *in
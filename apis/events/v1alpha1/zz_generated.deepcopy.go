@@ -1,3 +1,4 @@
+//go:build !ignore_autogenerated
 // +build !ignore_autogenerated
 
 /*
@@ -22,9 +23,8 @@ package v1alpha1
 
 import (
 	apiv1alpha1 "github.com/kedacore/keda/v2/api/v1alpha1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
-
 	corev1alpha1 "github.com/openfunction/apis/core/v1alpha1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -22,33 +22,38 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	openfunction "github.com/openfunction/apis/core/v1alpha2"
 	"github.com/openfunction/pkg/core"
 	"github.com/openfunction/pkg/core/builder/shipwright"
+	"github.com/openfunction/pkg/metrics"
 	"github.com/openfunction/pkg/util"
 )
 
 // BuilderReconciler reconciles a Builder object
 type BuilderReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
-	ctx    context.Context
-	timers map[string]*time.Timer
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	ctx      context.Context
 }
 
 func NewBuilderReconciler(mgr manager.Manager) *BuilderReconciler {
 
 	r := &BuilderReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Builder"),
-		timers: make(map[string]*time.Timer),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Log:      ctrl.Log.WithName("controllers").WithName("Builder"),
+		Recorder: mgr.GetEventRecorderFor("builder-controller"),
 	}
 
 	return r
@@ -57,6 +62,9 @@ func NewBuilderReconciler(mgr manager.Manager) *BuilderReconciler {
 //+kubebuilder:rbac:groups=core.openfunction.io,resources=builders,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core.openfunction.io,resources=builders/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=shipwright.io,resources=builds;buildruns,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -68,7 +76,10 @@ func NewBuilderReconciler(mgr manager.Manager) *BuilderReconciler {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.8.3/pkg/reconcile
 func (r *BuilderReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	r.ctx = ctx
+	// Work on a copy of the reconciler, so that the concurrent reconciles do not share the context.
+	rc := *r
+	rc.ctx = ctx
+	r = &rc
 	log := r.Log.WithValues("Builder", req.NamespacedName)
 
 	builder := &openfunction.Builder{}
@@ -76,7 +87,6 @@ func (r *BuilderReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	if err := r.Get(ctx, req.NamespacedName, builder); err != nil {
 		if util.IsNotFound(err) {
 			log.V(1).Info("Builder deleted")
-			r.stopTimer(req.NamespacedName.String())
 		}
 		return ctrl.Result{}, util.IgnoreNotFound(err)
 	}
@@ -87,22 +97,50 @@ func (r *BuilderReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	// Build timeout, update builder status.
-	// when the operator restarts, update the status of builder that has timed out, without creating a timer.
-	if builder.Spec.Timeout != nil &&
-		time.Since(builder.CreationTimestamp.Time) > builder.Spec.Timeout.Duration {
+	// The deadline is computed from the start time persisted in the status, so it survives the restart of the operator.
+	deadline, hasDeadline := buildDeadline(builder)
+	if hasDeadline && !time.Now().Before(deadline) {
+		// Cancel the build, so that it does not keep running after timeout.
+		if builderRun := r.createBuilderRun(builder); !util.InterfaceIsNil(builderRun) {
+			if err := builderRun.Clean(builder); err != nil {
+				log.Error(err, "Failed to clean builder")
+				return ctrl.Result{}, err
+			}
+		}
+
+		builder.Status.Phase = openfunction.BuildPhase
 		builder.Status.State = openfunction.Timeout
-		if err := r.Status().Update(r.ctx, builder); err != nil {
+		if err := r.updateStatus(builder); err != nil {
 			log.Error(err, "Failed to update builder status")
 			return ctrl.Result{}, err
 		}
 
+		metrics.IncTimeout(metrics.KindBuilder)
+		r.Recorder.Eventf(builder, corev1.EventTypeWarning, BuildTimeout, "Build timed out after %s", builder.Spec.Timeout.Duration)
 		return ctrl.Result{}, nil
 	}
 
-	// Start timer if build is not completed.
-	r.startTimer(builder)
+	// Reconcile again at the deadline, so that the build times out even if nothing else changes.
+	result := ctrl.Result{}
+	if hasDeadline {
+		result.RequeueAfter = time.Until(deadline)
+	}
 
-	builderRun := r.createBuilderRun()
+	builderRun := r.createBuilderRun(builder)
+	if util.InterfaceIsNil(builderRun) {
+		engine := getEngine(builder)
+		log.Error(nil, "Unknown engine", "engine", engine)
+		builder.Status.Phase = openfunction.BuildPhase
+		builder.Status.State = openfunction.Failed
+		builder.Status.Reason = UnknownEngine
+		builder.Status.Message = fmt.Sprintf("Unknown engine %s", engine)
+		if err := r.updateStatus(builder); err != nil {
+			log.Error(err, "Failed to update builder status")
+			return ctrl.Result{}, err
+		}
+		r.Recorder.Eventf(builder, corev1.EventTypeWarning, UnknownEngine, "Unknown engine %s", engine)
+		return ctrl.Result{}, nil
+	}
 
 	// If Builder had created, Update the status of the builder according to the result of the build.
 	if builder.Status.Phase != "" && builder.Status.State != "" {
@@ -110,36 +148,54 @@ func (r *BuilderReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			return ctrl.Result{}, err
 		}
 
-		return ctrl.Result{}, nil
+		return result, nil
 	}
 
-	// Reset builder status.
-	builder.Status = openfunction.BuilderStatus{}
-	if err := r.Status().Update(r.ctx, builder); err != nil {
+	// Reset builder status, the timeout is counted from the first attempt to start the build.
+	start := metav1.Now()
+	if builder.Status.StartTime != nil {
+		start = *builder.Status.StartTime
+	}
+	builder.Status = openfunction.BuilderStatus{StartTime: &start}
+	if err := r.updateStatus(builder); err != nil {
 		log.Error(err, "Failed to reset builder status")
 		return ctrl.Result{}, err
 	}
 
 	if err := builderRun.Start(builder); err != nil {
 		log.Error(err, "Failed to start builder")
+		r.Recorder.Eventf(builder, corev1.EventTypeWarning, BuildStartFailed, "Failed to start build: %s", err)
 		return ctrl.Result{}, err
 	}
 
 	builder.Status.Phase = openfunction.BuildPhase
 	builder.Status.State = openfunction.Building
-	if err := r.Status().Update(r.ctx, builder); err != nil {
+	if err := r.updateStatus(builder); err != nil {
 		log.Error(err, "Failed to update builder status")
 		return ctrl.Result{}, err
 	}
 
+	r.Recorder.Event(builder, corev1.EventTypeNormal, BuildStarted, "Build started")
 	log.V(1).Info("Builder is running")
 
-	return ctrl.Result{}, nil
+	if deadline, ok := buildDeadline(builder); ok {
+		result.RequeueAfter = time.Until(deadline)
+	}
+	return result, nil
+}
+
+func (r *BuilderReconciler) createBuilderRun(builder *openfunction.Builder) core.BuilderRun {
+
+	return core.NewBuilderRun(string(getEngine(builder)), r.ctx, r.Client, r.Scheme, r.Log)
 }
 
-func (r *BuilderReconciler) createBuilderRun() core.BuilderRun {
+// The builders created before the engine was introduced are built by Shipwright.
+func getEngine(builder *openfunction.Builder) openfunction.Engine {
+	if builder.Spec.Engine == nil {
+		return openfunction.Shipwright
+	}
 
-	return shipwright.NewBuildRun(r.ctx, r.Client, r.Scheme, r.Log)
+	return *builder.Spec.Engine
 }
 
 // Update the status of the builder according to the result of the build.
@@ -160,77 +216,67 @@ func (r *BuilderReconciler) getBuilderResult(builder *openfunction.Builder, buil
 
 	if res != builder.Status.State {
 		builder.Status.State = res
-		if err := r.Status().Update(r.ctx, builder); err != nil {
+		if err := r.updateStatus(builder); err != nil {
 			return err
 		}
 
-		r.stopTimer(fmt.Sprintf("%s/%s", builder.Namespace, builder.Name))
+		r.observeBuild(builder, res)
+		if res == openfunction.Succeeded {
+			r.Recorder.Event(builder, corev1.EventTypeNormal, BuildSucceeded, "Build succeeded")
+		} else {
+			r.Recorder.Eventf(builder, corev1.EventTypeWarning, BuildFailed, "Build %s: %s", res, builder.Status.Summary())
+		}
 		log.V(1).Info("Update builder status", "state", res)
 	}
 
 	return nil
 }
 
-func (r *BuilderReconciler) startTimer(builder *openfunction.Builder) {
-	namespacedName := fmt.Sprintf("%s/%s", builder.Namespace, builder.Name)
-	log := r.Log.WithName("Timer").WithValues("Builder", namespacedName)
-
-	if builder.Spec.Timeout == nil ||
-		time.Since(builder.CreationTimestamp.Time) > builder.Spec.Timeout.Duration {
-		return
+// Record the duration of the build, the duration is calculated from the creation of the builder
+// if the build did not report its start and completion time.
+func (r *BuilderReconciler) observeBuild(builder *openfunction.Builder, res string) {
+	start := builder.CreationTimestamp.Time
+	if builder.Status.StartTime != nil {
+		start = builder.Status.StartTime.Time
 	}
 
-	// Skipped when timer had started.
-	t, ok := r.timers[namespacedName]
-	if ok {
-		return
+	end := time.Now()
+	if builder.Status.CompletionTime != nil {
+		end = builder.Status.CompletionTime.Time
 	}
 
-	t = time.NewTimer(builder.Spec.Timeout.Duration - time.Since(builder.CreationTimestamp.Time))
-	r.timers[namespacedName] = t
-
-	go func() {
-
-		defer r.stopTimer(namespacedName)
+	// The engines other than Shipwright have no strategy, the engine is recorded instead.
+	strategy := string(getEngine(builder))
+	if getEngine(builder) == openfunction.Shipwright {
+		strategy = shipwright.GetStrategyName(builder)
+	}
 
-		select {
-		case <-t.C:
-			b := &openfunction.Builder{}
-			if err := r.Get(r.ctx, client.ObjectKeyFromObject(builder), b); err != nil {
-				if util.IsNotFound(err) {
-					log.Info("Builder had delete")
-					return
-				}
+	metrics.ObserveBuild(strategy, res, end.Sub(start))
+}
 
-				log.Error(err, "Failed to get Builder")
-				return
-			}
+// The deadline of the build, the builders started before the start time was recorded count from their creation.
+func buildDeadline(builder *openfunction.Builder) (time.Time, bool) {
+	if builder.Spec.Timeout == nil {
+		return time.Time{}, false
+	}
 
-			if !b.Status.IsCompleted() {
-				log.Error(nil, "Build timeout")
-				builder.Status.State = openfunction.Timeout
-				if err := r.Status().Update(r.ctx, builder); err != nil {
-					log.Error(err, "Failed to update builder status")
-				}
-			}
-		}
-	}()
+	start := builder.CreationTimestamp.Time
+	if builder.Status.StartTime != nil {
+		start = builder.Status.StartTime.Time
+	}
 
-	log.V(1).Info("Timer started")
+	return start.Add(builder.Spec.Timeout.Duration), true
 }
 
-func (r *BuilderReconciler) stopTimer(key string) {
-	log := r.Log.WithName("Timer").WithValues("Builder", key)
-	t := r.timers[key]
-	if t != nil {
-		t.Stop()
-		delete(r.timers, key)
-		log.Info("Timer stopped")
-	}
+// Update the status of the builder, the conditions are synced with the builder state.
+func (r *BuilderReconciler) updateStatus(builder *openfunction.Builder) error {
+	builder.Status.ObservedGeneration = builder.Generation
+	builder.Status.SyncConditions(builder.Generation)
+	return r.Status().Update(r.ctx, builder)
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *BuilderReconciler) SetupWithManager(mgr ctrl.Manager, owns []client.Object) error {
+func (r *BuilderReconciler) SetupWithManager(mgr ctrl.Manager, owns []client.Object, opts controller.Options) error {
 
 	b := ctrl.NewControllerManagedBy(mgr).
 		For(&openfunction.Builder{})
@@ -239,5 +285,5 @@ func (r *BuilderReconciler) SetupWithManager(mgr ctrl.Manager, owns []client.Obj
 		b.Owns(own)
 	}
 
-	return b.Complete(r)
+	return b.WithOptions(opts).Complete(r)
 }
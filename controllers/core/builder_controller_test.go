@@ -0,0 +1,71 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+)
+
+func TestBuildDeadline(t *testing.T) {
+	created := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	started := created.Add(time.Minute)
+
+	tests := []struct {
+		name    string
+		timeout *metav1.Duration
+		start   *metav1.Time
+		want    time.Time
+		wantOK  bool
+	}{
+		{
+			name: "no timeout",
+		},
+		{
+			name:    "counts from the creation if not started",
+			timeout: &metav1.Duration{Duration: 10 * time.Minute},
+			want:    created.Add(10 * time.Minute),
+			wantOK:  true,
+		},
+		{
+			name:    "counts from the start time",
+			timeout: &metav1.Duration{Duration: 10 * time.Minute},
+			start:   &metav1.Time{Time: started},
+			want:    started.Add(10 * time.Minute),
+			wantOK:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := &openfunction.Builder{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.Time{Time: created}},
+				Spec:       openfunction.BuilderSpec{Timeout: tt.timeout},
+				Status:     openfunction.BuilderStatus{StartTime: tt.start},
+			}
+
+			got, ok := buildDeadline(builder)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("buildDeadline() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
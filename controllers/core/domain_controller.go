@@ -19,40 +19,58 @@ package core
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
 	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	openfunction "github.com/openfunction/apis/core/v1alpha2"
+	"github.com/openfunction/pkg/config"
+	"github.com/openfunction/pkg/metrics"
 	"github.com/openfunction/pkg/util"
 )
 
-const (
-	reloadTimestamp = "reloadtimestamp"
-)
+// How often the domain is reconciled while the service of the ingress controller or the Gateway is not found.
+const serviceResolveInterval = 30 * time.Second
 
 // DomainReconciler reconciles a Domain object
 type DomainReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
-	ctx    context.Context
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// APIReader reads the services of the ingress controllers, which may be out of the namespaces watched by the cache.
+	APIReader client.Reader
+	ctx       context.Context
 }
 
 func NewDomainReconciler(mgr manager.Manager) *DomainReconciler {
 
 	r := &DomainReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Domain"),
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Log:       ctrl.Log.WithName("controllers").WithName("Domain"),
+		Recorder:  mgr.GetEventRecorderFor("domain-controller"),
+		APIReader: mgr.GetAPIReader(),
 	}
 
 	return r
@@ -61,6 +79,8 @@ func NewDomainReconciler(mgr manager.Manager) *DomainReconciler {
 //+kubebuilder:rbac:groups=core.openfunction.io,resources=domains,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core.openfunction.io,resources=domains/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=core.openfunction.io,resources=functions,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -72,7 +92,10 @@ func NewDomainReconciler(mgr manager.Manager) *DomainReconciler {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.8.3/pkg/reconcile
 func (r *DomainReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	r.ctx = ctx
+	// Work on a copy of the reconciler, so that the concurrent reconciles do not share the context.
+	rc := *r
+	rc.ctx = ctx
+	r = &rc
 	log := r.Log.WithValues("Domain", req.NamespacedName)
 
 	var d openfunction.Domain
@@ -91,6 +114,19 @@ func (r *DomainReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		},
 	}
 
+	resolved, err := r.resolveService(&d)
+	if err != nil {
+		log.Error(err, "Failed to resolve service")
+		return ctrl.Result{}, err
+	}
+
+	// The service of the ingress controller or the Gateway is not watched, as it may be out of the namespaces
+	// watched by the cache, so the domain is reconciled again until the service is found.
+	result := ctrl.Result{}
+	if !resolved {
+		result.RequeueAfter = serviceResolveInterval
+	}
+
 	if err := r.Get(r.ctx, client.ObjectKeyFromObject(svc), svc); util.IgnoreNotFound(err) != nil {
 		log.Error(err, "Failed to get service")
 		return ctrl.Result{}, err
@@ -99,27 +135,111 @@ func (r *DomainReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	op, err := controllerutil.CreateOrUpdate(context.TODO(), r.Client, svc, r.mutateService(&d, svc))
 	if err != nil {
 		log.Error(err, "Failed to CreateOrUpdate domain")
+		metrics.IncDomainUpdateError(fmt.Sprintf("%s/%s", d.Namespace, d.Name))
+		r.Recorder.Eventf(&d, corev1.EventTypeWarning, DomainUpdateFailed, "Failed to update service %s: %s", svc.Name, err)
+		d.Status.SetCondition(openfunction.ServiceCreated, metav1.ConditionFalse, "CreateFailed", err.Error(), d.Generation)
+		if err := r.updateStatus(&d); err != nil {
+			log.Error(err, "Failed to update domain status")
+		}
+		return ctrl.Result{}, err
+	}
+	d.Status.SetCondition(openfunction.ServiceCreated, metav1.ConditionTrue, "Created", fmt.Sprintf("Service %s is created", svc.Name), d.Generation)
+
+	if err := r.updateFunctionsStatus(&d); err != nil {
+		log.Error(err, "Failed to list functions of domain")
 		return ctrl.Result{}, err
 	}
 
-	if err := r.updateFunction(); err != nil {
-		log.Error(err, "Failed to update function")
+	if err := r.updateStatus(&d); err != nil {
+		log.Error(err, "Failed to update domain status")
 		return ctrl.Result{}, err
 	}
 
+	if op != controllerutil.OperationResultNone {
+		r.Recorder.Eventf(&d, corev1.EventTypeNormal, DomainUpdated, "Service %s %s", svc.Name, op)
+	}
+
 	log.V(1).Info(fmt.Sprintf("Domain %s", op))
-	return ctrl.Result{}, nil
+	return result, nil
+}
+
+// Check whether the service of the ingress controller or the Gateway exists.
+func (r *DomainReconciler) resolveService(d *openfunction.Domain) (bool, error) {
+	service := d.GetService()
+	svc := &corev1.Service{}
+	err := r.APIReader.Get(r.ctx, client.ObjectKey{Namespace: service.Namespace, Name: service.Name}, svc)
+	switch {
+	case err == nil:
+		d.Status.SetCondition(openfunction.ServiceResolved, metav1.ConditionTrue, "Found",
+			fmt.Sprintf("Service %s/%s is found", service.Namespace, service.Name), d.Generation)
+		return true, nil
+	case util.IsNotFound(err):
+		d.Status.SetCondition(openfunction.ServiceResolved, metav1.ConditionFalse, "NotFound",
+			fmt.Sprintf("Service %s/%s is not found", service.Namespace, service.Name), d.Generation)
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// Record the base url of the domain and the functions routed through it.
+// The functions are looked up by the domain index registered by the function reconciler,
+// the index also has the functions which reference the domain but are not routed through it yet.
+func (r *DomainReconciler) updateFunctionsStatus(d *openfunction.Domain) error {
+	name := fmt.Sprintf("%s/%s", d.Namespace, d.Name)
+	fnList := &openfunction.FunctionList{}
+	if err := r.List(r.ctx, fnList, client.MatchingFields{domainIndexKey: name}); err != nil {
+		return err
+	}
+
+	var functions []string
+	for _, fn := range fnList.Items {
+		for _, address := range fn.Status.Addresses {
+			if address.Domain == name {
+				functions = append(functions, fmt.Sprintf("%s/%s", fn.Namespace, fn.Name))
+				break
+			}
+		}
+	}
+	sort.Strings(functions)
+
+	d.Status.URL = domainURL(d)
+	d.Status.Functions = functions
+	d.Status.FunctionCount = int32(len(functions))
+	return nil
+}
+
+// The base url of the functions, the functions are at {url}/{function-namespace}/{function-name} in `Path` routing mode,
+// or at {function-name}.{function-namespace}.{host} in `Host` routing mode.
+func domainURL(d *openfunction.Domain) string {
+	service := d.GetService()
+	scheme, port, defaultPort := "http", service.Port, int32(80)
+	if d.UseTLS() {
+		scheme, port, defaultPort = "https", service.HTTPSPort, 443
+	}
+
+	host := fmt.Sprintf("%s.%s", d.Name, d.Namespace)
+	if d.IsHostRouting() {
+		host = d.Spec.Host
+	}
+
+	url := fmt.Sprintf("%s://%s", scheme, host)
+	if port != 0 && port != defaultPort {
+		url = fmt.Sprintf("%s:%d", url, port)
+	}
+
+	return url
 }
 
 func (r *DomainReconciler) mutateService(d *openfunction.Domain, svc *corev1.Service) controllerutil.MutateFn {
 
 	return func() error {
 
-		svc.Spec.ExternalName =
-			fmt.Sprintf("%s.%s.svc.cluster.local", d.Spec.Ingress.Service.Name, d.Spec.Ingress.Service.Namespace)
+		service := d.GetService()
+		svc.Spec.ExternalName = config.Get().ServiceHost(service.Name, service.Namespace)
 		svc.Spec.Type = corev1.ServiceTypeExternalName
 
-		port := d.Spec.Ingress.Service.Port
+		port := service.Port
 		if port == 0 {
 			port = 80
 		}
@@ -135,29 +255,78 @@ func (r *DomainReconciler) mutateService(d *openfunction.Domain, svc *corev1.Ser
 			},
 		}
 
+		if d.UseTLS() {
+			httpsPort := service.HTTPSPort
+			if httpsPort == 0 {
+				httpsPort = 443
+			}
+
+			svc.Spec.Ports = append(svc.Spec.Ports, corev1.ServicePort{
+				Name:     "https",
+				Port:     httpsPort,
+				Protocol: corev1.ProtocolTCP,
+				TargetPort: intstr.IntOrString{
+					IntVal: httpsPort,
+				},
+			})
+		}
+
 		return controllerutil.SetControllerReference(d, svc, r.Scheme)
 	}
 }
 
-func (r *DomainReconciler) updateFunction() error {
-	fnList := &openfunction.FunctionList{}
-	if err := r.List(r.ctx, fnList); err != nil {
-		return err
+// Update the status of the domain, the Ready condition is synced with the other conditions.
+func (r *DomainReconciler) updateStatus(d *openfunction.Domain) error {
+	d.Status.ObservedGeneration = d.Generation
+	d.Status.SyncConditions(d.Generation)
+	return r.Status().Update(r.ctx, d)
+}
+
+// Enqueue the domains which the function is or was routed through.
+func (r *DomainReconciler) domainsOfFunction(obj client.Object) []reconcile.Request {
+	fn, ok := obj.(*openfunction.Function)
+	if !ok {
+		return nil
 	}
 
-	for _, fn := range fnList.Items {
-		fn.Annotations[reloadTimestamp] = time.Now().String()
-		if err := r.Update(r.ctx, &fn); err != nil {
-			return err
+	var requests []reconcile.Request
+	for _, address := range fn.Status.Addresses {
+		namespace, name, err := cache.SplitMetaNamespaceKey(address.Domain)
+		if err != nil {
+			continue
 		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}})
 	}
 
-	return nil
+	return requests
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *DomainReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *DomainReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+
+	// Only the changes of the addresses of functions affect the status of domains.
+	addressesChanged := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldFn, ok := e.ObjectOld.(*openfunction.Function)
+			if !ok {
+				return false
+			}
+			newFn, ok := e.ObjectNew.(*openfunction.Function)
+			if !ok {
+				return false
+			}
+
+			return !reflect.DeepEqual(oldFn.Status.Addresses, newFn.Status.Addresses)
+		},
+	}
 
+	// Status updates of the domain itself do not need to be reconciled.
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&openfunction.Domain{}).Complete(r)
+		For(&openfunction.Domain{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Owns(&corev1.Service{}).
+		Watches(&source.Kind{Type: &openfunction.Function{}},
+			handler.EnqueueRequestsFromMapFunc(r.domainsOfFunction),
+			builder.WithPredicates(addressesChanged)).
+		WithOptions(opts).
+		Complete(r)
 }
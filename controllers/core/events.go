@@ -0,0 +1,60 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+// Reasons of the events recorded by the reconcilers.
+const (
+	BuilderCreated      = "BuilderCreated"
+	BuilderCreateFailed = "BuilderCreateFailed"
+	BuilderCleaned      = "BuilderCleaned"
+	BuildStarted        = "BuildStarted"
+	BuildStartFailed    = "BuildStartFailed"
+	BuildSucceeded      = "BuildSucceeded"
+	BuildFailed         = "BuildFailed"
+	BuildTimeout        = "BuildTimeout"
+	UnknownEngine       = "UnknownEngine"
+
+	ServingCreated      = "ServingCreated"
+	ServingCreateFailed = "ServingCreateFailed"
+	ServingCleaned      = "ServingCleaned"
+	ServingStarting     = "ServingStarting"
+	ServingStartFailed  = "ServingStartFailed"
+	ServingRunning      = "ServingRunning"
+	ServingFailed       = "ServingFailed"
+	ServingTimeout      = "ServingTimeout"
+	UnknownRuntime      = "UnknownRuntime"
+
+	RolloutStarted   = "RolloutStarted"
+	RolloutStep      = "RolloutStep"
+	RolloutCompleted = "RolloutCompleted"
+	RolloutAborted   = "RolloutAborted"
+	RolledBack       = "RolledBack"
+
+	IngressUpdated      = "IngressUpdated"
+	IngressUpdateFailed = "IngressUpdateFailed"
+	IngressCleaned      = "IngressCleaned"
+	IngressCleanFailed  = "IngressCleanFailed"
+
+	HTTPRouteUpdated      = "HTTPRouteUpdated"
+	HTTPRouteUpdateFailed = "HTTPRouteUpdateFailed"
+
+	SourceRevisionMoved = "SourceRevisionMoved"
+	SourceResolveFailed = "SourceResolveFailed"
+
+	DomainUpdated      = "DomainUpdated"
+	DomainUpdateFailed = "DomainUpdateFailed"
+)
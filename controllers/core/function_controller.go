@@ -18,36 +18,81 @@ package core
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	openfunction "github.com/openfunction/apis/core/v1alpha2"
 	"github.com/openfunction/pkg/constants"
+	"github.com/openfunction/pkg/metrics"
 	"github.com/openfunction/pkg/util"
 )
 
 const (
 	defaultIngressName = "openfunction"
+	functionFinalizer  = "openfunction.io/finalizer"
+
+	canaryIngressSuffix = "canary"
+	canaryAnnotation    = "nginx.ingress.kubernetes.io/canary"
+	canaryWeight        = "nginx.ingress.kubernetes.io/canary-weight"
+
+	certManagerIssuer        = "cert-manager.io/issuer"
+	certManagerClusterIssuer = "cert-manager.io/cluster-issuer"
+
+	upstreamVhost = "nginx.ingress.kubernetes.io/upstream-vhost"
+
+	defaultServicePort = 80
+
+	defaultRevisionHistoryLimit = 10
+
+	defaultAutoRebuildInterval = 5 * time.Minute
+
+	domainIndexKey   = "domain"
+	defaultDomainKey = "default"
 )
 
 // FunctionReconciler reconciles a Function object
 type FunctionReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
-	ctx    context.Context
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// APIReader reads the secrets of the git credentials, so that the secrets are not cached.
+	APIReader client.Reader
+	// The time the source revision of the functions was last resolved, the key is {namespace}/{name} of the function.
+	// It is kept in memory, so that the status is only written when the commit resolved changes.
+	resolveTimes *sync.Map
+	ctx          context.Context
 }
 
 //+kubebuilder:rbac:groups=core.openfunction.io,resources=functions,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core.openfunction.io,resources=functions/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core.openfunction.io,resources=functions/finalizers,verbs=update
 //+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.x-k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -59,7 +104,10 @@ type FunctionReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.8.3/pkg/reconcile
 func (r *FunctionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	r.ctx = ctx
+	// Work on a copy of the reconciler, so that the concurrent reconciles do not share the context.
+	rc := *r
+	rc.ctx = ctx
+	r = &rc
 	log := r.Log.WithValues("Function", req.NamespacedName)
 
 	fn := openfunction.Function{
@@ -73,12 +121,30 @@ func (r *FunctionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 
 		if util.IsNotFound(err) {
 			log.V(1).Info("Function deleted")
-			return ctrl.Result{}, r.cleanDefaultIngress(&fn)
+			r.resolveTimes.Delete(req.NamespacedName.String())
 		}
 
 		return ctrl.Result{}, util.IgnoreNotFound(err)
 	}
 
+	// The function is being deleted, clean up the resources which are not owned by it.
+	if !fn.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.finalize(&fn)
+	}
+
+	if !controllerutil.ContainsFinalizer(&fn, functionFinalizer) {
+		controllerutil.AddFinalizer(&fn, functionFinalizer)
+		if err := r.Update(ctx, &fn); err != nil {
+			log.Error(err, "Failed to add finalizer")
+			return ctrl.Result{}, err
+		}
+	}
+
+	resolveAfter, err := r.resolveSource(&fn)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	if err := r.createBuilder(&fn); err != nil {
 		return ctrl.Result{}, err
 	}
@@ -87,11 +153,153 @@ func (r *FunctionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, err
 	}
 
+	requeueAfter, err := r.progressRollout(&fn)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	if err := r.createOrUpdateService(&fn); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	if resolveAfter > 0 && (requeueAfter == 0 || resolveAfter < requeueAfter) {
+		requeueAfter = resolveAfter
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// Resolve the source revision to a commit when `spec.build.srcRepo.autoRebuild` is set.
+// The builder builds the commit resolved, so a new build is triggered when the revision moves to a new commit.
+// It returns the duration after which the revision should be resolved again.
+func (r *FunctionReconciler) resolveSource(fn *openfunction.Function) (time.Duration, error) {
+	log := r.Log.WithName("ResolveSource").
+		WithValues("Function", fmt.Sprintf("%s/%s", fn.Namespace, fn.Name))
+
+	if fn.Spec.Build == nil || fn.Spec.Build.SrcRepo == nil || fn.Spec.Build.SrcRepo.AutoRebuild == nil {
+		if fn.Status.Source == nil {
+			return 0, nil
+		}
+
+		fn.Status.Source = nil
+		if err := r.updateStatus(fn); err != nil {
+			log.Error(err, "Failed to update function source status")
+			return 0, err
+		}
+		return 0, nil
+	}
+
+	repo := fn.Spec.Build.SrcRepo
+	interval := defaultAutoRebuildInterval
+	if repo.AutoRebuild.Interval != nil && repo.AutoRebuild.Interval.Duration > 0 {
+		interval = repo.AutoRebuild.Interval.Duration
+	}
+
+	revision := ""
+	if repo.Revision != nil {
+		revision = *repo.Revision
+	}
+
+	// The commit resolved is out of date if the repository or revision changed.
+	key := types.NamespacedName{Namespace: fn.Namespace, Name: fn.Name}.String()
+	source := fn.Status.Source
+	changed := false
+	if source == nil || source.Url != repo.Url || source.Revision != revision {
+		source = &openfunction.SourceStatus{
+			Url:      repo.Url,
+			Revision: revision,
+		}
+		changed = true
+	} else {
+		var last time.Time
+		if source.LastResolveTime != nil {
+			last = source.LastResolveTime.Time
+		}
+		if t, ok := r.resolveTimes.Load(key); ok && t.(time.Time).After(last) {
+			last = t.(time.Time)
+		}
+		if elapsed := time.Since(last); elapsed < interval {
+			return interval - elapsed, nil
+		}
+	}
+
+	username, password, err := r.getGitCredentials(fn)
+	if err != nil {
+		log.Error(err, "Failed to get git credentials")
+		return 0, err
+	}
+
+	commit, err := util.ResolveGitRevision(r.ctx, repo.Url, revision, username, password)
+	if err != nil {
+		// Keep the commit resolved last time and retry in the next interval.
+		log.Error(err, "Failed to resolve source revision")
+		r.Recorder.Eventf(fn, corev1.EventTypeWarning, SourceResolveFailed, "Failed to resolve revision %q of %s: %s", revision, repo.Url, err)
+	} else if commit != source.Commit {
+		if source.Commit != "" {
+			r.Recorder.Eventf(fn, corev1.EventTypeNormal, SourceRevisionMoved, "Revision %q moved from %s to %s", revision, source.Commit, commit)
+		}
+		log.V(1).Info("Source revision resolved", "revision", revision, "commit", commit)
+		source.Commit = commit
+		changed = true
+	}
+
+	now := metav1.Now()
+	r.resolveTimes.Store(key, now.Time)
+	if !changed {
+		return interval, nil
+	}
+
+	source.LastResolveTime = &now
+	fn.Status.Source = source
+	if err := r.updateStatus(fn); err != nil {
+		log.Error(err, "Failed to update function source status")
+		return 0, err
+	}
+
+	return interval, nil
+}
+
+// Get the username and password in the credentials of the source repository.
+func (r *FunctionReconciler) getGitCredentials(fn *openfunction.Function) (string, string, error) {
+	credentials := fn.Spec.Build.SrcRepo.Credentials
+	if credentials == nil || credentials.Name == "" {
+		return "", "", nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.APIReader.Get(r.ctx, client.ObjectKey{Namespace: fn.Namespace, Name: credentials.Name}, secret); err != nil {
+		return "", "", err
+	}
+
+	return string(secret.Data[corev1.BasicAuthUsernameKey]), string(secret.Data[corev1.BasicAuthPasswordKey]), nil
+}
+
+// Clean up the shared resources which can not be garbage collected by owner references,
+// such as the path of the function in the default ingress, then remove the finalizer.
+func (r *FunctionReconciler) finalize(fn *openfunction.Function) error {
+	log := r.Log.WithName("Finalize").
+		WithValues("Function", fmt.Sprintf("%s/%s", fn.Namespace, fn.Name))
+
+	if !controllerutil.ContainsFinalizer(fn, functionFinalizer) {
+		return nil
+	}
+
+	if err := r.cleanIngress(fn, nil); err != nil {
+		log.Error(err, "Failed to clean ingress")
+		metrics.IncIngressUpdateError(fn.Namespace)
+		r.Recorder.Eventf(fn, corev1.EventTypeWarning, IngressCleanFailed, "Failed to clean ingress: %s", err)
+		return err
+	}
+	r.Recorder.Event(fn, corev1.EventTypeNormal, IngressCleaned, "Function path is removed from the ingresses")
+
+	controllerutil.RemoveFinalizer(fn, functionFinalizer)
+	if err := r.Update(r.ctx, fn); err != nil {
+		log.Error(err, "Failed to remove finalizer")
+		return util.IgnoreNotFound(err)
+	}
+
+	log.V(1).Info("Function finalized")
+	return nil
 }
 
 func (r *FunctionReconciler) createBuilder(fn *openfunction.Function) error {
@@ -113,7 +321,7 @@ func (r *FunctionReconciler) createBuilder(fn *openfunction.Function) error {
 	}
 	fn.Status.Build.State = ""
 	fn.Status.Build.ResourceRef = ""
-	if err := r.Status().Update(r.ctx, fn); err != nil {
+	if err := r.updateStatus(fn); err != nil {
 		log.Error(err, "Failed to reset function build status")
 		return err
 	}
@@ -130,7 +338,7 @@ func (r *FunctionReconciler) createBuilder(fn *openfunction.Function) error {
 			ResourceHash: util.Hash(openfunction.BuilderSpec{}),
 		}
 		fn.Status.Serving = &openfunction.Condition{}
-		if err := r.Status().Update(r.ctx, fn); err != nil {
+		if err := r.updateStatus(fn); err != nil {
 			log.Error(err, "Failed to update function build status")
 			return err
 		}
@@ -157,15 +365,17 @@ func (r *FunctionReconciler) createBuilder(fn *openfunction.Function) error {
 
 	if err := r.Create(r.ctx, builder); err != nil {
 		log.Error(err, "Failed to create builder")
+		r.Recorder.Eventf(fn, corev1.EventTypeWarning, BuilderCreateFailed, "Failed to create builder: %s", err)
 		return err
 	}
+	r.Recorder.Eventf(fn, corev1.EventTypeNormal, BuilderCreated, "Builder %s created", builder.Name)
 
 	fn.Status.Build = &openfunction.Condition{
 		State:        openfunction.Created,
 		ResourceRef:  builder.Name,
 		ResourceHash: util.Hash(builder.Spec),
 	}
-	if err := r.Status().Update(r.ctx, fn); err != nil {
+	if err := r.updateStatus(fn); err != nil {
 		log.Error(err, "Failed to update function build status")
 		return err
 	}
@@ -206,8 +416,21 @@ func (r *FunctionReconciler) updateFuncWithBuilderStatus(fn *openfunction.Functi
 	// If builder status changed, update function build status.
 	if fn.Status.Build.State != builder.Status.State {
 		fn.Status.Build.State = builder.Status.State
+		fn.Status.Build.Reason = builder.Status.Reason
+		fn.Status.Build.Message = builder.Status.Summary()
+		r.recordBuildEvent(fn, &builder)
 		// If build had complete, update function serving status.
 		if builder.Status.State == openfunction.Succeeded {
+			if fn.Status.Source != nil && builder.Spec.SrcRepo != nil && builder.Spec.SrcRepo.Revision != nil {
+				fn.Status.Source.BuiltCommit = *builder.Spec.SrcRepo.Revision
+			}
+
+			// Pin the serving to the image built, the image is not pinned if the build did not report the digest.
+			fn.Status.Image = ""
+			if builder.Status.Digest != "" {
+				fn.Status.Image = fmt.Sprintf("%s@%s", builder.Spec.Image, builder.Status.Digest)
+			}
+
 			if fn.Status.Serving == nil {
 				fn.Status.Serving = &openfunction.Condition{}
 			}
@@ -216,7 +439,7 @@ func (r *FunctionReconciler) updateFuncWithBuilderStatus(fn *openfunction.Functi
 		}
 	}
 
-	if err := r.Status().Update(r.ctx, fn); err != nil {
+	if err := r.updateStatus(fn); err != nil {
 		log.Error(err, "Failed to update function status")
 		return err
 	}
@@ -228,6 +451,19 @@ func (r *FunctionReconciler) updateFuncWithBuilderStatus(fn *openfunction.Functi
 	return nil
 }
 
+func (r *FunctionReconciler) recordBuildEvent(fn *openfunction.Function, builder *openfunction.Builder) {
+	switch builder.Status.State {
+	case openfunction.Succeeded:
+		r.Recorder.Eventf(fn, corev1.EventTypeNormal, BuildSucceeded, "Builder %s succeeded", builder.Name)
+	case openfunction.Timeout:
+		r.Recorder.Eventf(fn, corev1.EventTypeWarning, BuildTimeout, "Builder %s timed out", builder.Name)
+	case openfunction.Building:
+		// The start of build is recorded by the builder.
+	default:
+		r.Recorder.Eventf(fn, corev1.EventTypeWarning, BuildFailed, "Builder %s %s: %s", builder.Name, builder.Status.State, builder.Status.Summary())
+	}
+}
+
 func (r *FunctionReconciler) cleanupBuilder(fn *openfunction.Function) error {
 	log := r.Log.WithName("CleanupBuilder").
 		WithValues("Function", fmt.Sprintf("%s/%s", fn.Namespace, fn.Name))
@@ -247,6 +483,7 @@ func (r *FunctionReconciler) cleanupBuilder(fn *openfunction.Function) error {
 		return util.IgnoreNotFound(err)
 	}
 
+	r.Recorder.Eventf(fn, corev1.EventTypeNormal, BuilderCleaned, "Builder %s cleaned up", builder.Name)
 	log.V(1).Info("Function builder cleanup", "Builder", builder.Name)
 	return nil
 }
@@ -277,6 +514,8 @@ func (r *FunctionReconciler) createBuilderSpec(fn *openfunction.Function) openfu
 		return openfunction.BuilderSpec{}
 	}
 
+	// The engine is left unset unless the user sets it, the builder reconciler defaults it to Shipwright.
+	// The unset engine is kept out of the hash, see BuilderSpec.HashInclude.
 	spec := openfunction.BuilderSpec{
 		Params:             fn.Spec.Build.Params,
 		Env:                fn.Spec.Build.Env,
@@ -286,12 +525,22 @@ func (r *FunctionReconciler) createBuilderSpec(fn *openfunction.Function) openfu
 		ImageCredentials:   fn.Spec.ImageCredentials,
 		Port:               fn.Spec.Port,
 		Timeout:            fn.Spec.Build.Timeout,
+		Shipwright:         fn.Spec.Build.Shipwright,
+		Kaniko:             fn.Spec.Build.Kaniko,
+		Dockerfile:         fn.Spec.Build.Dockerfile,
+		Engine:             fn.Spec.Build.Engine,
 	}
 
 	spec.SrcRepo = &openfunction.GitRepo{}
 	spec.SrcRepo.Init()
 	fn.Spec.Build.SrcRepo.DeepCopyInto(spec.SrcRepo)
 
+	// Build the commit resolved from the revision, so that the function is rebuilt when the revision moves.
+	if spec.SrcRepo.AutoRebuild != nil && fn.Status.Source != nil && fn.Status.Source.Commit != "" {
+		commit := fn.Status.Source.Commit
+		spec.SrcRepo.Revision = &commit
+	}
+
 	return spec
 }
 
@@ -309,6 +558,12 @@ func (r *FunctionReconciler) createServing(fn *openfunction.Function) error {
 		return nil
 	}
 
+	// A new serving will be created, abort the rollout in progress and keep the stable serving.
+	if err := r.abortRollout(fn); err != nil {
+		log.Error(err, "Failed to abort rollout")
+		return err
+	}
+
 	// Reset function serving status.
 	if fn.Status.Serving == nil {
 		fn.Status.Serving = &openfunction.Condition{}
@@ -316,7 +571,7 @@ func (r *FunctionReconciler) createServing(fn *openfunction.Function) error {
 	fn.Status.Serving.State = ""
 	fn.Status.Serving.ResourceRef = ""
 	fn.Status.Serving.ResourceHash = ""
-	if err := r.Status().Update(r.ctx, fn); err != nil {
+	if err := r.updateStatus(fn); err != nil {
 		log.Error(err, "Failed to update function serving status")
 		return err
 	}
@@ -331,7 +586,7 @@ func (r *FunctionReconciler) createServing(fn *openfunction.Function) error {
 			State:        openfunction.Skipped,
 			ResourceHash: util.Hash(openfunction.ServingSpec{}),
 		}
-		if err := r.Status().Update(r.ctx, fn); err != nil {
+		if err := r.updateStatus(fn); err != nil {
 			log.Error(err, "Failed to update function serving status")
 			return err
 		}
@@ -348,8 +603,14 @@ func (r *FunctionReconciler) createServing(fn *openfunction.Function) error {
 				constants.FunctionLabel: fn.Name,
 			},
 		},
-		Spec: r.createServingSpec(fn),
+		Spec: r.desiredServingSpec(fn),
+	}
+	traffic, err := r.servingTraffic(fn, &serving.Spec)
+	if err != nil {
+		log.Error(err, "Failed to get stable serving")
+		return err
 	}
+	serving.Spec.Traffic = traffic
 	serving.SetOwnerReferences(nil)
 	if err := ctrl.SetControllerReference(fn, serving, r.Scheme); err != nil {
 		log.Error(err, "Failed to SetOwnerReferences for serving")
@@ -358,16 +619,21 @@ func (r *FunctionReconciler) createServing(fn *openfunction.Function) error {
 
 	if err := r.Create(r.ctx, serving); err != nil {
 		log.Error(err, "Failed to create serving")
+		r.Recorder.Eventf(fn, corev1.EventTypeWarning, ServingCreateFailed, "Failed to create serving: %s", err)
 		return err
 	}
+	r.Recorder.Eventf(fn, corev1.EventTypeNormal, ServingCreated, "Serving %s created", serving.Name)
 
 	fn.Status.Serving = &openfunction.Condition{
 		State:                     openfunction.Created,
 		ResourceRef:               serving.Name,
 		ResourceHash:              util.Hash(serving.Spec),
 		LastSuccessfulResourceRef: fn.Status.Serving.LastSuccessfulResourceRef,
+		Service:                   fn.Status.Serving.Service,
+		ServicePort:               fn.Status.Serving.ServicePort,
+		ServiceHost:               fn.Status.Serving.ServiceHost,
 	}
-	if err := r.Status().Update(r.ctx, fn); err != nil {
+	if err := r.updateStatus(fn); err != nil {
 		log.Error(err, "Failed to update function serving status")
 		return err
 	}
@@ -409,19 +675,38 @@ func (r *FunctionReconciler) updateFuncWithServingStatus(fn *openfunction.Functi
 	if fn.Status.Serving.State != serving.Status.State {
 		fn.Status.Serving.State = serving.Status.State
 
-		// If new serving is running, clean old serving.
+		// If new serving is running, shift traffic to it step by step if a rollout policy is defined,
+		// else clean old serving.
 		if serving.Status.State == openfunction.Running {
-			fn.Status.Serving.LastSuccessfulResourceRef = fn.Status.Serving.ResourceRef
-			fn.Status.Serving.Service = serving.Status.Service
-			if err := r.cleanServing(fn); err != nil {
-				log.Error(err, "Failed to clean Serving")
-				return err
+			r.recordRevision(fn, &serving)
+			r.Recorder.Eventf(fn, corev1.EventTypeNormal, ServingRunning, "Serving %s is running", serving.Name)
+			if r.needRollout(fn, &serving) {
+				r.startRollout(fn, &serving)
+				r.Recorder.Eventf(fn, corev1.EventTypeNormal, RolloutStarted, "Shift %d%% traffic to serving %s", fn.Status.Rollout.Weight, serving.Name)
+				log.V(1).Info("Serving is running, start rollout", "serving", serving.Name)
+			} else {
+				fn.Status.Serving.LastSuccessfulResourceRef = fn.Status.Serving.ResourceRef
+				fn.Status.Serving.Service = serving.Status.Service
+				fn.Status.Serving.ServicePort = serving.Status.ServicePort
+				fn.Status.Serving.ServiceHost = serving.Status.ServiceHost
+				if err := r.cleanServing(fn); err != nil {
+					log.Error(err, "Failed to clean Serving")
+					return err
+				}
+				log.V(1).Info("Serving is running", "serving", serving.Name)
+			}
+		} else if serving.Status.IsFailed() {
+			r.recordServingFailedEvent(fn, &serving)
+			if r.needAutoRollback(fn, &serving) {
+				if err := r.autoRollback(fn, &serving); err != nil {
+					log.Error(err, "Failed to roll back")
+					return err
+				}
 			}
-			log.V(1).Info("Serving is running", "serving", serving.Name)
 		}
 	}
 
-	if err := r.Status().Update(r.ctx, fn); err != nil {
+	if err := r.updateStatus(fn); err != nil {
 		log.Error(err, "Failed to update function status")
 		return err
 	}
@@ -429,6 +714,270 @@ func (r *FunctionReconciler) updateFuncWithServingStatus(fn *openfunction.Functi
 	return nil
 }
 
+func (r *FunctionReconciler) recordServingFailedEvent(fn *openfunction.Function, serving *openfunction.Serving) {
+	switch serving.Status.State {
+	case openfunction.Timeout:
+		r.Recorder.Eventf(fn, corev1.EventTypeWarning, ServingTimeout, "Serving %s timed out", serving.Name)
+	case openfunction.UnknownRuntime:
+		r.Recorder.Eventf(fn, corev1.EventTypeWarning, UnknownRuntime, "Serving %s uses an unknown runtime", serving.Name)
+	default:
+		r.Recorder.Eventf(fn, corev1.EventTypeWarning, ServingFailed, "Serving %s failed: %s", serving.Name, serving.Status.State)
+	}
+}
+
+// The traffic split of a new serving. When both the new and the stable serving use the Knative runtime,
+// the new serving splits the traffic between their revisions itself during a rollout,
+// it routes all traffic to the stable revision until the rollout starts.
+func (r *FunctionReconciler) servingTraffic(fn *openfunction.Function, spec *openfunction.ServingSpec) (*openfunction.ServingTraffic, error) {
+	if fn.Spec.Serving == nil ||
+		fn.Spec.Serving.Rollout == nil ||
+		len(fn.Spec.Serving.Rollout.Steps) == 0 ||
+		spec.Runtime == nil ||
+		*spec.Runtime != openfunction.Knative ||
+		fn.Status.Serving == nil ||
+		fn.Status.Serving.LastSuccessfulResourceRef == "" {
+		return nil, nil
+	}
+
+	stable := &openfunction.Serving{}
+	key := client.ObjectKey{Namespace: fn.Namespace, Name: fn.Status.Serving.LastSuccessfulResourceRef}
+	if err := r.Get(r.ctx, key, stable); err != nil {
+		return nil, util.IgnoreNotFound(err)
+	}
+
+	if stable.Spec.Runtime == nil || *stable.Spec.Runtime != openfunction.Knative {
+		return nil, nil
+	}
+
+	return &openfunction.ServingTraffic{StableServing: stable.Name}, nil
+}
+
+// Determine whether the traffic should be shifted to the new serving step by step.
+func (r *FunctionReconciler) needRollout(fn *openfunction.Function, serving *openfunction.Serving) bool {
+	// The serving splits the traffic with the stable serving, the split must be removed by the rollout.
+	if serving.Spec.Traffic != nil {
+		return true
+	}
+
+	if fn.Spec.Serving == nil ||
+		fn.Spec.Serving.Rollout == nil ||
+		len(fn.Spec.Serving.Rollout.Steps) == 0 {
+		return false
+	}
+
+	// Only Knative and Plain runtime expose a service which can receive traffic.
+	if fn.Spec.Serving.Runtime != nil &&
+		*fn.Spec.Serving.Runtime != openfunction.Knative &&
+		*fn.Spec.Serving.Runtime != openfunction.Plain {
+		return false
+	}
+
+	// There is no stable serving to shift traffic from,
+	// or the stable serving is routed by host which the canary ingress can not do.
+	// The canary ingress is the fallback for the servings which can not split the traffic themselves.
+	return fn.Status.Serving.LastSuccessfulResourceRef != "" &&
+		fn.Status.Serving.LastSuccessfulResourceRef != fn.Status.Serving.ResourceRef &&
+		fn.Status.Serving.Service != "" &&
+		fn.Status.Serving.ServiceHost == ""
+}
+
+func (r *FunctionReconciler) startRollout(fn *openfunction.Function, serving *openfunction.Serving) {
+	fn.Status.Rollout = &openfunction.RolloutStatus{
+		StableServing: fn.Status.Serving.LastSuccessfulResourceRef,
+		StableService: fn.Status.Serving.Service,
+		CanaryServing: serving.Name,
+		CanaryService: serving.Status.Service,
+		// The port of the canary service may differ from the stable one, such as a Plain serving with a custom port.
+		CanaryServicePort: serving.Status.ServicePort,
+		SplitByServing:    serving.Spec.Traffic != nil,
+		Step:              0,
+		Weight:            fn.Spec.Serving.Rollout.Steps[0].Weight,
+		StepStartTime:     metav1.Now(),
+	}
+}
+
+// Move the rollout to the next step when the pause of the current step is over,
+// and cut over all traffic to the canary serving after the last step.
+// It returns the time to wait before the current step is over.
+func (r *FunctionReconciler) progressRollout(fn *openfunction.Function) (time.Duration, error) {
+	log := r.Log.WithName("ProgressRollout").
+		WithValues("Function", fmt.Sprintf("%s/%s", fn.Namespace, fn.Name))
+
+	rollout := fn.Status.Rollout
+	if rollout == nil {
+		return 0, nil
+	}
+
+	var steps []openfunction.RolloutStep
+	if fn.Spec.Serving != nil && fn.Spec.Serving.Rollout != nil {
+		steps = fn.Spec.Serving.Rollout.Steps
+	}
+
+	previous := *rollout
+	for int(rollout.Step) < len(steps) {
+		step := steps[rollout.Step]
+		if step.Pause != nil {
+			if elapsed := time.Since(rollout.StepStartTime.Time); elapsed < step.Pause.Duration {
+				rollout.Weight = step.Weight
+				if _, err := r.syncRollout(fn, &previous, false); err != nil {
+					log.Error(err, "Failed to sync function rollout")
+					return 0, err
+				}
+
+				if rollout.Weight != previous.Weight || rollout.Step != previous.Step {
+					r.Recorder.Eventf(fn, corev1.EventTypeNormal, RolloutStep, "Shift %d%% traffic to serving %s", rollout.Weight, rollout.CanaryServing)
+					log.V(1).Info("Rollout step started", "step", rollout.Step, "weight", rollout.Weight)
+				}
+
+				return step.Pause.Duration - elapsed, nil
+			}
+		}
+
+		rollout.Step++
+		rollout.StepStartTime = metav1.Now()
+	}
+
+	// All steps are done, cut over all traffic to the canary serving.
+	// The stable serving must be kept until the canary serving stops routing traffic to it,
+	// the function is reconciled again when the status of the canary serving changes.
+	if rollout.SplitByServing {
+		rollout.Weight = 100
+		applied, err := r.syncRollout(fn, &previous, true)
+		if err != nil {
+			log.Error(err, "Failed to sync function rollout")
+			return 0, err
+		}
+
+		if !applied {
+			log.V(1).Info("Wait for the canary serving to route all traffic to itself", "serving", rollout.CanaryServing)
+			return 0, nil
+		}
+	}
+
+	fn.Status.Serving.LastSuccessfulResourceRef = rollout.CanaryServing
+	fn.Status.Serving.Service = rollout.CanaryService
+	fn.Status.Serving.ServicePort = rollout.CanaryServicePort
+	fn.Status.Serving.ServiceHost = ""
+	fn.Status.Rollout = nil
+	if err := r.updateStatus(fn); err != nil {
+		log.Error(err, "Failed to update function rollout status")
+		return 0, err
+	}
+
+	if err := r.cleanServing(fn); err != nil {
+		log.Error(err, "Failed to clean Serving")
+		return 0, err
+	}
+
+	r.Recorder.Eventf(fn, corev1.EventTypeNormal, RolloutCompleted, "All traffic is shifted to serving %s", rollout.CanaryServing)
+	log.V(1).Info("Rollout completed", "serving", rollout.CanaryServing)
+	return 0, nil
+}
+
+// Persist the progress of the rollout, and sync the traffic split of the canary serving if it splits the traffic itself.
+// It returns true once the canary serving applied the traffic split.
+// All traffic is routed to the canary serving once the rollout is done.
+func (r *FunctionReconciler) syncRollout(fn *openfunction.Function, previous *openfunction.RolloutStatus, done bool) (bool, error) {
+	rollout := fn.Status.Rollout
+
+	applied := true
+	if rollout.SplitByServing {
+		var traffic *openfunction.ServingTraffic
+		if !done {
+			traffic = &openfunction.ServingTraffic{
+				StableServing: rollout.StableServing,
+				CanaryWeight:  rollout.Weight,
+			}
+		}
+
+		serving := &openfunction.Serving{}
+		key := client.ObjectKey{Namespace: fn.Namespace, Name: rollout.CanaryServing}
+		if err := r.Get(r.ctx, key, serving); err != nil {
+			return false, err
+		}
+
+		if !reflect.DeepEqual(serving.Spec.Traffic, traffic) {
+			serving.Spec.Traffic = traffic
+			if err := r.Update(r.ctx, serving); err != nil {
+				return false, err
+			}
+		}
+		applied = reflect.DeepEqual(serving.Status.Traffic, traffic)
+	}
+
+	if reflect.DeepEqual(rollout, previous) {
+		return applied, nil
+	}
+
+	if err := r.updateStatus(fn); err != nil {
+		return false, err
+	}
+	*previous = *rollout
+	return applied, nil
+}
+
+// Stop shifting traffic to the canary serving, the traffic will go back to the stable serving.
+// The caller is responsible for updating the function status.
+func (r *FunctionReconciler) abortRollout(fn *openfunction.Function) error {
+	if fn.Status.Rollout == nil {
+		return nil
+	}
+
+	if err := r.cleanCanaryIngress(fn); err != nil {
+		return err
+	}
+
+	r.Recorder.Eventf(fn, corev1.EventTypeNormal, RolloutAborted, "Rollout to serving %s is aborted", fn.Status.Rollout.CanaryServing)
+	r.Log.WithName("AbortRollout").
+		WithValues("Function", fmt.Sprintf("%s/%s", fn.Namespace, fn.Name)).
+		V(1).Info("Rollout aborted", "serving", fn.Status.Rollout.CanaryServing)
+	fn.Status.Rollout = nil
+	return nil
+}
+
+func (r *FunctionReconciler) needAutoRollback(fn *openfunction.Function, serving *openfunction.Serving) bool {
+	if fn.Spec.Serving == nil || !fn.Spec.Serving.AutoRollback || !serving.Status.IsFailed() {
+		return false
+	}
+
+	// There is no successful serving to roll back to.
+	return fn.Status.Serving.LastSuccessfulResourceRef != "" &&
+		fn.Status.Serving.LastSuccessfulResourceRef != fn.Status.Serving.ResourceRef
+}
+
+// Restore the last successful serving as the active serving and delete the failed one.
+// The resource hash of the failed serving is kept, so it will not be re-created until the function changed.
+func (r *FunctionReconciler) autoRollback(fn *openfunction.Function, serving *openfunction.Serving) error {
+	log := r.Log.WithName("AutoRollback").
+		WithValues("Function", fmt.Sprintf("%s/%s", fn.Namespace, fn.Name))
+
+	if err := r.abortRollout(fn); err != nil {
+		return err
+	}
+
+	last := fn.Status.Serving.LastSuccessfulResourceRef
+	fn.Status.LastRollback = &openfunction.RollbackStatus{
+		FailedServing: serving.Name,
+		Serving:       last,
+		Reason:        serving.Status.State,
+		Message:       fmt.Sprintf("Serving %s is %s, rolled back to serving %s", serving.Name, serving.Status.State, last),
+		Timestamp:     metav1.Now(),
+	}
+	fn.Status.Serving.ResourceRef = last
+	fn.Status.Serving.State = openfunction.Running
+	if err := r.updateStatus(fn); err != nil {
+		return err
+	}
+
+	if err := r.cleanServing(fn); err != nil {
+		return err
+	}
+
+	r.Recorder.Event(fn, corev1.EventTypeWarning, RolledBack, fn.Status.LastRollback.Message)
+	log.Info("Serving failed, rolled back", "failed", serving.Name, "serving", last, "state", serving.Status.State)
+	return nil
+}
+
 // Clean up redundant servings caused by the `createOrUpdateBuilder` function failed.
 func (r *FunctionReconciler) cleanServing(fn *openfunction.Function) error {
 	log := r.Log.WithName("CleanServing").
@@ -451,6 +1000,7 @@ func (r *FunctionReconciler) cleanServing(fn *openfunction.Function) error {
 			if err := r.Delete(context.Background(), &item); util.IgnoreNotFound(err) != nil {
 				return err
 			}
+			r.Recorder.Eventf(fn, corev1.EventTypeNormal, ServingCleaned, "Serving %s cleaned up", item.Name)
 			log.V(1).Info("Delete Serving", "Serving", item.Name)
 		}
 	}
@@ -471,6 +1021,11 @@ func (r *FunctionReconciler) createServingSpec(fn *openfunction.Function) openfu
 		Timeout:          fn.Spec.Serving.Timeout,
 	}
 
+	// Run the image built by the function instead of the mutable tag.
+	if fn.Spec.Build != nil {
+		spec.Image = fn.Status.PinnedImage(fn.Spec.Image)
+	}
+
 	if fn.Spec.Port != nil {
 		port := *fn.Spec.Port
 		spec.Port = &port
@@ -479,6 +1034,8 @@ func (r *FunctionReconciler) createServingSpec(fn *openfunction.Function) openfu
 	if fn.Spec.Serving != nil {
 		spec.Params = fn.Spec.Serving.Params
 		spec.OpenFuncAsync = fn.Spec.Serving.OpenFuncAsync
+		spec.Plain = fn.Spec.Serving.Plain
+		spec.KedaHTTP = fn.Spec.Serving.KedaHTTP
 		spec.Template = fn.Spec.Serving.Template
 	}
 
@@ -492,10 +1049,109 @@ func (r *FunctionReconciler) createServingSpec(fn *openfunction.Function) openfu
 	return spec
 }
 
+// Get the serving spec the function should run.
+// If `spec.rollbackTo` is set, it is the serving spec of the revision to roll back to.
+func (r *FunctionReconciler) desiredServingSpec(fn *openfunction.Function) openfunction.ServingSpec {
+	log := r.Log.WithName("DesiredServingSpec").
+		WithValues("Function", fmt.Sprintf("%s/%s", fn.Namespace, fn.Name))
+
+	if fn.Spec.Serving == nil || fn.Spec.RollbackTo == nil {
+		return r.createServingSpec(fn)
+	}
+
+	revision := fn.Status.GetRevision(*fn.Spec.RollbackTo)
+	if revision == nil {
+		log.Error(nil, "Revision to roll back to not found, use the latest spec", "revision", *fn.Spec.RollbackTo)
+		return r.createServingSpec(fn)
+	}
+
+	spec := openfunction.ServingSpec{}
+	if err := json.Unmarshal(revision.ServingSpec.Raw, &spec); err != nil {
+		log.Error(err, "Failed to decode serving spec of revision, use the latest spec", "revision", *fn.Spec.RollbackTo)
+		return r.createServingSpec(fn)
+	}
+
+	// Run the exact image of the revision even if its tag has been pushed again.
+	if revision.Digest != "" && !strings.Contains(spec.Image, "@") {
+		spec.Image = fmt.Sprintf("%s@%s", spec.Image, revision.Digest)
+	}
+
+	return spec
+}
+
+// Record the running serving to the revision history of the function.
+func (r *FunctionReconciler) recordRevision(fn *openfunction.Function, serving *openfunction.Serving) {
+	log := r.Log.WithName("RecordRevision").
+		WithValues("Function", fmt.Sprintf("%s/%s", fn.Namespace, fn.Name))
+
+	now := metav1.Now()
+	hash := util.Hash(serving.Spec)
+
+	// The serving had run before, such as rolling back to an old revision, just refresh the timestamp.
+	for i := range fn.Status.Revisions {
+		if fn.Status.Revisions[i].ServingHash == hash {
+			fn.Status.Revisions[i].Timestamp = now
+			return
+		}
+	}
+
+	var next int64 = 1
+	if n := len(fn.Status.Revisions); n > 0 {
+		next = fn.Status.Revisions[n-1].Revision + 1
+	}
+
+	// The traffic split only lives during the rollout of the serving.
+	spec := serving.Spec
+	spec.Traffic = nil
+	bs, err := json.Marshal(spec)
+	if err != nil {
+		log.Error(err, "Failed to encode serving spec")
+		return
+	}
+
+	revision := openfunction.Revision{
+		Revision:    next,
+		Image:       serving.Spec.Image,
+		ServingHash: hash,
+		ServingSpec: runtime.RawExtension{Raw: bs},
+		Timestamp:   now,
+	}
+	if fn.Status.Build != nil {
+		revision.BuilderHash = fn.Status.Build.ResourceHash
+	}
+	// The image of the serving is pinned by the digest resolved by the build.
+	if parts := strings.SplitN(serving.Spec.Image, "@", 2); len(parts) == 2 {
+		revision.Digest = parts[1]
+	}
+	fn.Status.Revisions = append(fn.Status.Revisions, revision)
+
+	limit := defaultRevisionHistoryLimit
+	if fn.Spec.RevisionHistoryLimit != nil {
+		limit = int(*fn.Spec.RevisionHistoryLimit)
+	}
+
+	for len(fn.Status.Revisions) > limit {
+		// Never drop the revision which the function rolled back to.
+		index := 0
+		if fn.Spec.RollbackTo != nil && fn.Status.Revisions[0].Revision == *fn.Spec.RollbackTo {
+			index = 1
+		}
+		fn.Status.Revisions = append(fn.Status.Revisions[:index], fn.Status.Revisions[index+1:]...)
+	}
+
+	log.V(1).Info("Revision recorded", "revision", next)
+}
+
 func (r *FunctionReconciler) needToCreateBuilder(fn *openfunction.Function) bool {
 	log := r.Log.WithName("NeedToCreateBuilder").
 		WithValues("Function", fmt.Sprintf("%s/%s", fn.Namespace, fn.Name))
 
+	// The serving of the revision to roll back to runs the image built before, no need to build.
+	if fn.Spec.RollbackTo != nil && fn.Status.GetRevision(*fn.Spec.RollbackTo) != nil {
+		log.V(1).Info("Rolling back, skip build", "revision", *fn.Spec.RollbackTo)
+		return false
+	}
+
 	// Builder had not created, need to create.
 	if fn.Status.Build == nil ||
 		fn.Status.Build.ResourceHash == "" ||
@@ -548,7 +1204,7 @@ func (r *FunctionReconciler) needToCreateServing(fn *openfunction.Function) bool
 		return true
 	}
 
-	newHash := util.Hash(r.createServingSpec(fn))
+	newHash := util.Hash(r.desiredServingSpec(fn))
 	// Serving changed, need to update.
 	if newHash != oldHash {
 		log.V(1).Info("Serving changed", "old", oldHash, "new", newHash)
@@ -582,78 +1238,332 @@ func (r *FunctionReconciler) createOrUpdateService(fn *openfunction.Function) er
 		return nil
 	}
 
-	dl := &openfunction.DomainList{}
-	if err := r.List(r.ctx, dl); err != nil {
-		log.Error(err, "Failed to list domain")
+	domains, err := r.selectDomains(fn)
+	if err != nil {
+		log.Error(err, "Failed to select domain")
+		metrics.IncIngressUpdateError(fn.Namespace)
+		r.Recorder.Eventf(fn, corev1.EventTypeWarning, IngressUpdateFailed, "Failed to select domain: %s", err)
 		return err
 	}
 
-	if len(dl.Items) == 0 {
-		log.Error(nil, "No Domain defined")
-		return fmt.Errorf("no Domain defined")
-	}
+	desired := make(map[string]bool)
+	desiredRoutes := make(map[string]bool)
+	for i := range domains {
+		if domains[i].UseGateway() {
+			desiredRoutes[httpRouteName(fn, &domains[i])] = true
+			continue
+		}
 
-	domain := &dl.Items[0]
+		desired[ingressName(fn, &domains[i])] = true
+		if splitByRoute(fn) {
+			desired[canaryIngressName(fn, &domains[i])] = true
+		}
+	}
 
-	if err := r.cleanIngress(fn); err != nil {
+	if err := r.cleanIngress(fn, desired); err != nil {
 		log.Error(err, "Failed to clean ingress")
 		return err
 	}
 
-	op, err := r.createOrUpdateIngress(fn, domain)
-	if err != nil {
-		log.Error(err, "Failed to createOrUpdate ingress")
+	if err := r.cleanHTTPRoute(fn, desiredRoutes); err != nil {
+		log.Error(err, "Failed to clean HTTPRoute")
 		return err
 	}
 
-	url := fmt.Sprintf("%s://%s.%s", "http", domain.Name, domain.Namespace)
-	if domain.Spec.Ingress.Service.Port != 0 && domain.Spec.Ingress.Service.Port != 80 {
-		url = fmt.Sprintf("%s:%d", url, domain.Spec.Ingress.Service.Port)
+	var addresses []openfunction.FunctionAddress
+	for i := range domains {
+		domain := &domains[i]
+
+		var err error
+		switch {
+		case domain.IsHostRouting() && domain.Spec.Host == "":
+			err = fmt.Errorf("domain %s is in Host routing mode but has no host", domain.Name)
+		}
+		if err != nil {
+			log.Error(err, "Invalid domain")
+			metrics.IncIngressUpdateError(fn.Namespace)
+			r.Recorder.Eventf(fn, corev1.EventTypeWarning, IngressUpdateFailed, "Failed to update ingress of domain %s: %s", domain.Name, err)
+			return err
+		}
+
+		if domain.UseGateway() {
+			op, err := r.createOrUpdateHTTPRoute(fn, domain)
+			if err != nil {
+				log.Error(err, "Failed to createOrUpdate HTTPRoute", "Domain", domain.Name)
+				metrics.IncIngressUpdateError(fn.Namespace)
+				r.Recorder.Eventf(fn, corev1.EventTypeWarning, HTTPRouteUpdateFailed, "Failed to update HTTPRoute of domain %s: %s", domain.Name, err)
+				return err
+			}
+
+			if op != controllerutil.OperationResultNone {
+				r.Recorder.Eventf(fn, corev1.EventTypeNormal, HTTPRouteUpdated, "HTTPRoute of domain %s %s, route to service %s", domain.Name, op, fn.Status.Serving.Service)
+			}
+
+			addresses = append(addresses, openfunction.FunctionAddress{
+				Domain: fmt.Sprintf("%s/%s", domain.Namespace, domain.Name),
+				URL:    functionURL(fn, domain),
+			})
+			continue
+		}
+
+		op, err := r.createOrUpdateIngress(fn, domain)
+		if err != nil {
+			log.Error(err, "Failed to createOrUpdate ingress", "Domain", domain.Name)
+			metrics.IncIngressUpdateError(fn.Namespace)
+			r.Recorder.Eventf(fn, corev1.EventTypeWarning, IngressUpdateFailed, "Failed to update ingress of domain %s: %s", domain.Name, err)
+			return err
+		}
+
+		if err := r.createOrUpdateCanaryIngress(fn, domain); err != nil {
+			log.Error(err, "Failed to createOrUpdate canary ingress", "Domain", domain.Name)
+			metrics.IncIngressUpdateError(fn.Namespace)
+			r.Recorder.Eventf(fn, corev1.EventTypeWarning, IngressUpdateFailed, "Failed to update canary ingress of domain %s: %s", domain.Name, err)
+			return err
+		}
+
+		if op != controllerutil.OperationResultNone {
+			r.Recorder.Eventf(fn, corev1.EventTypeNormal, IngressUpdated, "Ingress of domain %s %s, route to service %s", domain.Name, op, fn.Status.Serving.Service)
+		}
+
+		addresses = append(addresses, openfunction.FunctionAddress{
+			Domain: fmt.Sprintf("%s/%s", domain.Namespace, domain.Name),
+			URL:    functionURL(fn, domain),
+		})
+		log.V(1).Info(fmt.Sprintf("Service %s", op), "Domain", domain.Name)
 	}
-	url = fmt.Sprintf("%s/%s/%s", url, fn.Namespace, fn.Name)
-	if url != fn.Status.URL {
-		fn.Status.URL = url
-		if err := r.Status().Update(r.ctx, fn); err != nil {
+
+	// The url on the first domain is the url of the function.
+	if addresses[0].URL != fn.Status.URL || !reflect.DeepEqual(addresses, fn.Status.Addresses) {
+		fn.Status.URL = addresses[0].URL
+		fn.Status.Addresses = addresses
+		if err := r.updateStatus(fn); err != nil {
 			log.Error(err, "Failed to update function url")
 			return err
 		}
 	}
 
-	log.V(1).Info(fmt.Sprintf("Service %s", op))
 	return nil
 }
 
-func (r *FunctionReconciler) cleanIngress(fn *openfunction.Function) error {
+// Select the domains through which the function is accessed, the first one is the domain of `status.url`.
+// The function uses the default domain if `spec.service.domainRef` is not set.
+func (r *FunctionReconciler) selectDomains(fn *openfunction.Function) ([]openfunction.Domain, error) {
+	dl := &openfunction.DomainList{}
+	if err := r.List(r.ctx, dl); err != nil {
+		return nil, err
+	}
 
-	// If the function use a standalone ingress, delete configuration from default ingress.
-	if fn.Spec.Service != nil && fn.Spec.Service.UseStandaloneIngress {
-		return r.cleanDefaultIngress(fn)
+	if len(dl.Items) == 0 {
+		return nil, fmt.Errorf("no Domain defined")
 	}
 
-	// The function use default ingress, delete the standalone ingress for function.
-	ingress := &networkingv1.Ingress{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      fn.Name,
-			Namespace: fn.Namespace,
-		},
+	var refs []openfunction.DomainReference
+	var domains []openfunction.Domain
+	if fn.Spec.Service != nil && fn.Spec.Service.DomainRef != nil {
+		refs = append(refs, *fn.Spec.Service.DomainRef)
+	} else {
+		domains = append(domains, *defaultDomain(dl.Items))
+	}
+
+	if fn.Spec.Service != nil {
+		refs = append(refs, fn.Spec.Service.AdditionalDomainRefs...)
 	}
 
-	return util.IgnoreNotFound(r.Delete(r.ctx, ingress))
+	for _, ref := range refs {
+		domain, err := findDomain(dl.Items, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		selected := false
+		for _, d := range domains {
+			if d.Namespace == domain.Namespace && d.Name == domain.Name {
+				selected = true
+				break
+			}
+		}
+
+		if !selected {
+			domains = append(domains, *domain)
+		}
+	}
+
+	return domains, nil
 }
 
-// Delete `path` from default ingress.
-func (r *FunctionReconciler) cleanDefaultIngress(fn *openfunction.Function) error {
-	ingress := &networkingv1.Ingress{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      defaultIngressName,
-			Namespace: fn.Namespace,
-		},
+// The default domain is the domain annotated with `openfunction.io/default-domain: "true"`.
+// If no domain is annotated, all domains are candidates. The oldest candidate is chosen, so that the choice is stable.
+func defaultDomain(domains []openfunction.Domain) *openfunction.Domain {
+	var candidates []openfunction.Domain
+	for _, d := range domains {
+		if d.Annotations[openfunction.DefaultDomainAnnotation] == "true" {
+			candidates = append(candidates, d)
+		}
 	}
 
-	if err := r.Get(r.ctx, client.ObjectKeyFromObject(ingress), ingress); err != nil {
-		return util.IgnoreNotFound(err)
+	if len(candidates) == 0 {
+		candidates = domains
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ti, tj := candidates[i].CreationTimestamp, candidates[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+
+		return fmt.Sprintf("%s/%s", candidates[i].Namespace, candidates[i].Name) <
+			fmt.Sprintf("%s/%s", candidates[j].Namespace, candidates[j].Name)
+	})
+
+	return &candidates[0]
+}
+
+func findDomain(domains []openfunction.Domain, ref openfunction.DomainReference) (*openfunction.Domain, error) {
+	var found []*openfunction.Domain
+	for i := range domains {
+		d := &domains[i]
+		if d.Name == ref.Name && (ref.Namespace == "" || d.Namespace == ref.Namespace) {
+			found = append(found, d)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("domain %s not found", ref.Name)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("domain %s exists in several namespaces, the namespace must be specified", ref.Name)
+	}
+}
+
+// The url of the function on the domain.
+func functionURL(fn *openfunction.Function, domain *openfunction.Domain) string {
+	service := domain.GetService()
+	scheme, port, defaultPort := "http", service.Port, int32(80)
+	if domain.UseTLS() {
+		scheme, port, defaultPort = "https", service.HTTPSPort, 443
+	}
+
+	host := fmt.Sprintf("%s.%s", domain.Name, domain.Namespace)
+	if domain.IsHostRouting() {
+		host = domain.FunctionHost(fn.Name, fn.Namespace)
 	}
 
+	url := fmt.Sprintf("%s://%s", scheme, host)
+	if port != 0 && port != defaultPort {
+		url = fmt.Sprintf("%s:%d", url, port)
+	}
+
+	if domain.IsHostRouting() {
+		return url
+	}
+
+	return fmt.Sprintf("%s/%s/%s", url, fn.Namespace, fn.Name)
+}
+
+// Each domain has its own ingress, the ingress is shared by the functions in the namespace,
+// or is owned by the function if it uses its own ingress.
+func ingressName(fn *openfunction.Function, domain *openfunction.Domain) string {
+	name := defaultIngressName
+	if useOwnIngress(fn, domain) {
+		name = fn.Name
+	}
+
+	return fmt.Sprintf("%s-%s-%s", name, domain.Namespace, domain.Name)
+}
+
+// The function uses its own ingress if it asks for a standalone ingress, or it is routed by host,
+// or its service needs a host header which can only be set on the whole ingress.
+func useOwnIngress(fn *openfunction.Function, domain *openfunction.Domain) bool {
+	return domain.IsHostRouting() ||
+		(fn.Spec.Service != nil && fn.Spec.Service.UseStandaloneIngress) ||
+		(fn.Status.Serving != nil && fn.Status.Serving.ServiceHost != "")
+}
+
+// The port of the service of the running serving.
+func servicePort(fn *openfunction.Function) int32 {
+	if fn.Status.Serving == nil || fn.Status.Serving.ServicePort == 0 {
+		return defaultServicePort
+	}
+
+	return fn.Status.Serving.ServicePort
+}
+
+// The port of the service of the canary serving.
+func canaryServicePort(fn *openfunction.Function) int32 {
+	if fn.Status.Rollout == nil || fn.Status.Rollout.CanaryServicePort == 0 {
+		return defaultServicePort
+	}
+
+	return fn.Status.Rollout.CanaryServicePort
+}
+
+// The service which the function routes all traffic to. During a rollout in which the canary serving
+// splits the traffic with the stable serving itself, it is the service of the canary serving.
+func routedService(fn *openfunction.Function) (string, int32) {
+	if fn.Status.Rollout != nil && fn.Status.Rollout.SplitByServing {
+		return fn.Status.Rollout.CanaryService, canaryServicePort(fn)
+	}
+
+	return fn.Status.Serving.Service, servicePort(fn)
+}
+
+// The ingresses or HTTPRoutes of the function split the traffic between the stable and the canary serving
+// only if the canary serving can not do it itself.
+func splitByRoute(fn *openfunction.Function) bool {
+	return fn.Status.Rollout != nil && !fn.Status.Rollout.SplitByServing
+}
+
+func canaryIngressName(fn *openfunction.Function, domain *openfunction.Domain) string {
+	return fmt.Sprintf("%s-%s-%s-%s", fn.Name, domain.Namespace, domain.Name, canaryIngressSuffix)
+}
+
+// Delete the ingresses owned by the function and remove the path of the function from the shared ingresses,
+// except the desired ones. All the ingresses of the function are cleaned if desired is nil.
+func (r *FunctionReconciler) cleanIngress(fn *openfunction.Function, desired map[string]bool) error {
+	ingresses := &networkingv1.IngressList{}
+	if err := r.List(r.ctx, ingresses, client.InNamespace(fn.Namespace)); err != nil {
+		return err
+	}
+
+	for i := range ingresses.Items {
+		ingress := &ingresses.Items[i]
+		if desired[ingress.Name] {
+			continue
+		}
+
+		if metav1.IsControlledBy(ingress, fn) {
+			if err := r.Delete(r.ctx, ingress); util.IgnoreNotFound(err) != nil {
+				return err
+			}
+			continue
+		}
+
+		if isSharedIngress(ingress) {
+			if err := r.removeIngressPath(fn, ingress); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// The shared ingress is not owned by any function. The ingress named `openfunction` is the shared ingress
+// created before the domain of function can be selected.
+func isSharedIngress(ingress *networkingv1.Ingress) bool {
+	if metav1.GetControllerOf(ingress) != nil {
+		return false
+	}
+
+	_, ok := ingress.Labels[constants.DomainLabel]
+	return ok || ingress.Name == defaultIngressName
+}
+
+// Delete `path` of the function from the shared ingress.
+func (r *FunctionReconciler) removeIngressPath(fn *openfunction.Function, ingress *networkingv1.Ingress) error {
+	found := false
 	for i := 0; i < len(ingress.Spec.Rules); i++ {
 		rule := ingress.Spec.Rules[i]
 		if rule.HTTP == nil {
@@ -663,6 +1573,7 @@ func (r *FunctionReconciler) cleanDefaultIngress(fn *openfunction.Function) erro
 		for i := 0; i < len(rule.HTTP.Paths); i++ {
 			if rule.HTTP.Paths[i].Path == fmt.Sprintf("/%s/%s(/|$)(.*)", fn.Namespace, fn.Name) {
 				rule.HTTP.Paths = append(rule.HTTP.Paths[:i], rule.HTTP.Paths[i+1:]...)
+				found = true
 				break
 			}
 		}
@@ -673,6 +1584,10 @@ func (r *FunctionReconciler) cleanDefaultIngress(fn *openfunction.Function) erro
 		}
 	}
 
+	if !found {
+		return nil
+	}
+
 	if len(ingress.Spec.Rules) == 0 {
 		return util.IgnoreNotFound(r.Delete(r.ctx, ingress))
 	}
@@ -682,14 +1597,9 @@ func (r *FunctionReconciler) cleanDefaultIngress(fn *openfunction.Function) erro
 
 func (r *FunctionReconciler) createOrUpdateIngress(fn *openfunction.Function, domain *openfunction.Domain) (controllerutil.OperationResult, error) {
 
-	name := defaultIngressName
-	if fn.Spec.Service != nil && fn.Spec.Service.UseStandaloneIngress {
-		name = fn.Name
-	}
-
 	ingress := &networkingv1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
+			Name:      ingressName(fn, domain),
 			Namespace: fn.Namespace,
 		},
 	}
@@ -705,28 +1615,29 @@ func (r *FunctionReconciler) createOrUpdateIngress(fn *openfunction.Function, do
 func (r *FunctionReconciler) mutateIngress(fn *openfunction.Function, domain *openfunction.Domain, ingress *networkingv1.Ingress) controllerutil.MutateFn {
 
 	return func() error {
-		path := createIngressPath(fn)
+		service, port := routedService(fn)
+		path := createIngressPath(fn, domain, service, port)
 		ingressClassName := domain.Spec.Ingress.IngressClassName
-		if fn.Spec.Service != nil && fn.Spec.Service.UseStandaloneIngress {
+		if useOwnIngress(fn, domain) {
 			ingress.Spec = networkingv1.IngressSpec{
 				IngressClassName: &ingressClassName,
+				TLS:              ingressTLS(fn, domain),
 				Rules: []networkingv1.IngressRule{
-					{
-						IngressRuleValue: networkingv1.IngressRuleValue{
-							HTTP: &networkingv1.HTTPIngressRuleValue{
-								Paths: []networkingv1.HTTPIngressPath{
-									path,
-								},
-							},
-						},
-					},
+					createIngressRule(fn, domain, path),
 				},
 			}
 
-			addAnnotations(ingress, domain.Spec.Ingress.Annotations)
+			addDomainAnnotations(ingress, domain)
 			if fn.Spec.Service != nil {
 				addAnnotations(ingress, fn.Spec.Service.Annotations)
 			}
+			if host := fn.Status.Serving.ServiceHost; host != "" {
+				addAnnotations(ingress, map[string]string{upstreamVhost: host})
+			} else {
+				delete(ingress.Annotations, upstreamVhost)
+			}
+			addDomainLabels(ingress, domain)
+			ingress.Labels[constants.FunctionLabel] = fn.Name
 
 			return controllerutil.SetControllerReference(fn, ingress, r.Scheme)
 
@@ -770,31 +1681,188 @@ func (r *FunctionReconciler) mutateIngress(fn *openfunction.Function, domain *op
 			}
 
 			ingress.Annotations = nil
-			addAnnotations(ingress, domain.Spec.Ingress.Annotations)
+			addDomainAnnotations(ingress, domain)
+			addDomainLabels(ingress, domain)
 			ingress.Spec.IngressClassName = &ingressClassName
+			ingress.Spec.TLS = ingressTLS(fn, domain)
 
 			return nil
 		}
 	}
 }
 
-func createIngressPath(fn *openfunction.Function) networkingv1.HTTPIngressPath {
+// The canary ingress routes a weighted part of the traffic of the function path to the canary serving.
+// It is the fallback for the servings which can not split the traffic with the stable serving themselves.
+func (r *FunctionReconciler) createOrUpdateCanaryIngress(fn *openfunction.Function, domain *openfunction.Domain) error {
+
+	if !splitByRoute(fn) {
+		return nil
+	}
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      canaryIngressName(fn, domain),
+			Namespace: fn.Namespace,
+		},
+	}
+
+	_, err := controllerutil.CreateOrUpdate(r.ctx, r.Client, ingress, r.mutateCanaryIngress(fn, domain, ingress))
+	return err
+}
+
+func (r *FunctionReconciler) mutateCanaryIngress(fn *openfunction.Function, domain *openfunction.Domain, ingress *networkingv1.Ingress) controllerutil.MutateFn {
+
+	return func() error {
+		ingressClassName := domain.Spec.Ingress.IngressClassName
+		ingress.Spec = networkingv1.IngressSpec{
+			IngressClassName: &ingressClassName,
+			TLS:              ingressTLS(fn, domain),
+			Rules: []networkingv1.IngressRule{
+				createIngressRule(fn, domain, createIngressPath(fn, domain, fn.Status.Rollout.CanaryService, canaryServicePort(fn))),
+			},
+		}
+
+		ingress.Annotations = nil
+		addDomainAnnotations(ingress, domain)
+		if fn.Spec.Service != nil && useOwnIngress(fn, domain) {
+			addAnnotations(ingress, fn.Spec.Service.Annotations)
+		}
+		addAnnotations(ingress, map[string]string{
+			canaryAnnotation: "true",
+			canaryWeight:     fmt.Sprintf("%d", fn.Status.Rollout.Weight),
+		})
+		addDomainLabels(ingress, domain)
+		ingress.Labels[constants.FunctionLabel] = fn.Name
+
+		return controllerutil.SetControllerReference(fn, ingress, r.Scheme)
+	}
+}
+
+// Delete the canary ingresses of the function on all domains.
+func (r *FunctionReconciler) cleanCanaryIngress(fn *openfunction.Function) error {
+	ingresses := &networkingv1.IngressList{}
+	if err := r.List(r.ctx, ingresses, client.InNamespace(fn.Namespace)); err != nil {
+		return err
+	}
+
+	for i := range ingresses.Items {
+		ingress := &ingresses.Items[i]
+		if !metav1.IsControlledBy(ingress, fn) || ingress.Annotations[canaryAnnotation] != "true" {
+			continue
+		}
+
+		if err := r.Delete(r.ctx, ingress); util.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// The rule of the function, it matches the host of the function in `Host` routing mode.
+func createIngressRule(fn *openfunction.Function, domain *openfunction.Domain, path networkingv1.HTTPIngressPath) networkingv1.IngressRule {
+	rule := networkingv1.IngressRule{
+		IngressRuleValue: networkingv1.IngressRuleValue{
+			HTTP: &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{
+					path,
+				},
+			},
+		},
+	}
+
+	if domain.IsHostRouting() {
+		rule.Host = domain.FunctionHost(fn.Name, fn.Namespace)
+	}
+
+	return rule
+}
+
+// The function is served at `/` in `Host` routing mode, or at `/{namespace}/{name}` in `Path` routing mode.
+func createIngressPath(fn *openfunction.Function, domain *openfunction.Domain, service string, port int32) networkingv1.HTTPIngressPath {
+
+	path := fmt.Sprintf("/%s/%s(/|$)(.*)", fn.Namespace, fn.Name)
+	if domain.IsHostRouting() {
+		path = "/"
+	}
 
 	pathType := networkingv1.PathTypePrefix
 	return networkingv1.HTTPIngressPath{
-		Path:     fmt.Sprintf("/%s/%s(/|$)(.*)", fn.Namespace, fn.Name),
+		Path:     path,
 		PathType: &pathType,
 		Backend: networkingv1.IngressBackend{
 			Service: &networkingv1.IngressServiceBackend{
-				Name: fn.Status.Serving.Service,
+				Name: service,
 				Port: networkingv1.ServiceBackendPort{
-					Number: 80,
+					Number: port,
 				},
 			},
 		},
 	}
 }
 
+// The hosts of the domain in `Path` routing mode, the host of the service of the domain in the cluster,
+// and the host of the domain if it is set.
+func domainHosts(domain *openfunction.Domain) []string {
+	hosts := []string{fmt.Sprintf("%s.%s", domain.Name, domain.Namespace)}
+	if domain.Spec.Host != "" {
+		hosts = append(hosts, domain.Spec.Host)
+	}
+
+	return hosts
+}
+
+// The annotations of the domain, including the annotations which ask cert-manager to issue the certificate.
+func addDomainAnnotations(ingress *networkingv1.Ingress, domain *openfunction.Domain) {
+	addAnnotations(ingress, domain.Spec.Ingress.Annotations)
+
+	tls := domain.Spec.Ingress.TLS
+	if tls == nil || tls.Issuer == nil {
+		return
+	}
+
+	if tls.Issuer.Kind == "Issuer" {
+		addAnnotations(ingress, map[string]string{certManagerIssuer: tls.Issuer.Name})
+	} else {
+		addAnnotations(ingress, map[string]string{certManagerClusterIssuer: tls.Issuer.Name})
+	}
+}
+
+// If the hosts are not specified, the certificate covers the host of the function in `Host` routing mode,
+// or the host of the domain in `Path` routing mode.
+func ingressTLS(fn *openfunction.Function, domain *openfunction.Domain) []networkingv1.IngressTLS {
+	tls := domain.Spec.Ingress.TLS
+	if tls == nil {
+		return nil
+	}
+
+	hosts := tls.Hosts
+	if len(hosts) == 0 {
+		if domain.IsHostRouting() {
+			hosts = []string{domain.FunctionHost(fn.Name, fn.Namespace)}
+		} else {
+			hosts = domainHosts(domain)
+		}
+	}
+
+	return []networkingv1.IngressTLS{
+		{
+			Hosts:      hosts,
+			SecretName: tls.SecretName,
+		},
+	}
+}
+
+// The domain labels identify the domain which the ingress belongs to.
+func addDomainLabels(ingress *networkingv1.Ingress, domain *openfunction.Domain) {
+	if ingress.Labels == nil {
+		ingress.Labels = make(map[string]string)
+	}
+
+	ingress.Labels[constants.DomainLabel] = domain.Name
+	ingress.Labels[constants.DomainNamespaceLabel] = domain.Namespace
+}
+
 func addAnnotations(ingress *networkingv1.Ingress, annotations map[string]string) {
 	if annotations == nil {
 		return
@@ -809,11 +1877,123 @@ func addAnnotations(ingress *networkingv1.Ingress, annotations map[string]string
 	}
 }
 
+// Update the status of the function, the conditions are synced with the build and serving state.
+func (r *FunctionReconciler) updateStatus(fn *openfunction.Function) error {
+	fn.Status.ObservedGeneration = fn.Generation
+	fn.Status.SyncConditions(fn.Generation)
+	return r.Status().Update(r.ctx, fn)
+}
+
+// The keys of the domains which a function is routed through or references, the key is {namespace}/{name}
+// of the domain, or */{name} if the domain is referenced without namespace. The function which uses the
+// default domain also has the key `defaultDomainKey`.
+func domainIndexValues(obj client.Object) []string {
+	fn, ok := obj.(*openfunction.Function)
+	if !ok {
+		return nil
+	}
+
+	var values []string
+	for _, address := range fn.Status.Addresses {
+		values = append(values, address.Domain)
+	}
+
+	var refs []openfunction.DomainReference
+	if fn.Spec.Service != nil {
+		if fn.Spec.Service.DomainRef != nil {
+			refs = append(refs, *fn.Spec.Service.DomainRef)
+		}
+		refs = append(refs, fn.Spec.Service.AdditionalDomainRefs...)
+	}
+
+	if fn.Spec.Service == nil || fn.Spec.Service.DomainRef == nil {
+		values = append(values, defaultDomainKey)
+	}
+
+	for _, ref := range refs {
+		if ref.Namespace == "" {
+			values = append(values, fmt.Sprintf("*/%s", ref.Name))
+		} else {
+			values = append(values, fmt.Sprintf("%s/%s", ref.Namespace, ref.Name))
+		}
+	}
+
+	return values
+}
+
+// Enqueue the functions affected by the domain. Besides the functions which are routed through or reference the domain,
+// the functions which use the default domain are enqueued when the default domain may change.
+func (r *FunctionReconciler) enqueueFunctionsOfDomain(domain *openfunction.Domain, defaultChanged bool, q workqueue.RateLimitingInterface) {
+	log := r.Log.WithName("EnqueueFunctionsOfDomain").
+		WithValues("Domain", fmt.Sprintf("%s/%s", domain.Namespace, domain.Name))
+
+	keys := []string{
+		fmt.Sprintf("%s/%s", domain.Namespace, domain.Name),
+		fmt.Sprintf("*/%s", domain.Name),
+	}
+	if defaultChanged {
+		keys = append(keys, defaultDomainKey)
+	}
+
+	for _, key := range keys {
+		fnList := &openfunction.FunctionList{}
+		if err := r.List(context.Background(), fnList, client.MatchingFields{domainIndexKey: key}); err != nil {
+			log.Error(err, "Failed to list functions of domain")
+			continue
+		}
+
+		for _, fn := range fnList.Items {
+			q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: fn.Namespace, Name: fn.Name}})
+		}
+	}
+}
+
+func (r *FunctionReconciler) domainEventHandler() handler.EventHandler {
+	return handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			if domain, ok := e.Object.(*openfunction.Domain); ok {
+				r.enqueueFunctionsOfDomain(domain, true, q)
+			}
+		},
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			oldDomain, ok := e.ObjectOld.(*openfunction.Domain)
+			if !ok {
+				return
+			}
+			newDomain, ok := e.ObjectNew.(*openfunction.Domain)
+			if !ok {
+				return
+			}
+
+			// The functions do not care about the status of the domain.
+			defaultChanged := oldDomain.Annotations[openfunction.DefaultDomainAnnotation] != newDomain.Annotations[openfunction.DefaultDomainAnnotation]
+			if oldDomain.Generation == newDomain.Generation && !defaultChanged {
+				return
+			}
+
+			r.enqueueFunctionsOfDomain(newDomain, defaultChanged, q)
+		},
+		DeleteFunc: func(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			if domain, ok := e.Object.(*openfunction.Domain); ok {
+				r.enqueueFunctionsOfDomain(domain, true, q)
+			}
+		},
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
-func (r *FunctionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *FunctionReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	r.resolveTimes = &sync.Map{}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &openfunction.Function{}, domainIndexKey, domainIndexValues); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&openfunction.Function{}).
 		Owns(&openfunction.Builder{}).
 		Owns(&openfunction.Serving{}).
+		Watches(&source.Kind{Type: &openfunction.Domain{}}, r.domainEventHandler()).
+		WithOptions(opts).
 		Complete(r)
 }
@@ -0,0 +1,1126 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1alpha1 "sigs.k8s.io/gateway-api/apis/v1alpha1"
+
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+	"github.com/openfunction/pkg/constants"
+	"github.com/openfunction/pkg/util"
+)
+
+func newDomain(namespace, name string, created time.Time, isDefault bool) openfunction.Domain {
+	d := openfunction.Domain{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			CreationTimestamp: metav1.Time{Time: created},
+		},
+	}
+	if isDefault {
+		d.Annotations = map[string]string{openfunction.DefaultDomainAnnotation: "true"}
+	}
+
+	return d
+}
+
+func domainKeys(domains []openfunction.Domain) []string {
+	var keys []string
+	for _, d := range domains {
+		keys = append(keys, d.Namespace+"/"+d.Name)
+	}
+
+	return keys
+}
+
+func TestDefaultDomain(t *testing.T) {
+	t0 := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		domains []openfunction.Domain
+		want    string
+	}{
+		{
+			name: "the oldest domain if none is annotated",
+			domains: []openfunction.Domain{
+				newDomain("ingress", "new", t1, false),
+				newDomain("ingress", "old", t0, false),
+			},
+			want: "ingress/old",
+		},
+		{
+			name: "the annotated domain",
+			domains: []openfunction.Domain{
+				newDomain("ingress", "old", t0, false),
+				newDomain("ingress", "new", t1, true),
+			},
+			want: "ingress/new",
+		},
+		{
+			name: "the oldest annotated domain",
+			domains: []openfunction.Domain{
+				newDomain("ingress", "a", t0, false),
+				newDomain("ingress", "b", t1, true),
+				newDomain("ingress", "c", t0, true),
+			},
+			want: "ingress/c",
+		},
+		{
+			name: "ordered by key if created at the same time",
+			domains: []openfunction.Domain{
+				newDomain("ingress", "b", t0, false),
+				newDomain("gateway", "b", t0, false),
+				newDomain("ingress", "a", t0, false),
+			},
+			want: "gateway/b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := defaultDomain(tt.domains)
+			if got := d.Namespace + "/" + d.Name; got != tt.want {
+				t.Errorf("defaultDomain() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectDomains(t *testing.T) {
+	t0 := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	domains := []openfunction.Domain{
+		newDomain("ingress", "openfunction", t0, true),
+		newDomain("ingress", "public", t0.Add(time.Hour), false),
+		newDomain("gateway", "public", t0.Add(time.Hour), false),
+		newDomain("gateway", "internal", t0.Add(time.Hour), false),
+	}
+
+	tests := []struct {
+		name    string
+		domains []openfunction.Domain
+		service *openfunction.ServiceImpl
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "no domain",
+			wantErr: true,
+		},
+		{
+			name:    "default domain",
+			domains: domains,
+			want:    []string{"ingress/openfunction"},
+		},
+		{
+			name:    "referenced domain without namespace",
+			domains: domains,
+			service: &openfunction.ServiceImpl{DomainRef: &openfunction.DomainReference{Name: "internal"}},
+			want:    []string{"gateway/internal"},
+		},
+		{
+			name:    "ambiguous domain",
+			domains: domains,
+			service: &openfunction.ServiceImpl{DomainRef: &openfunction.DomainReference{Name: "public"}},
+			wantErr: true,
+		},
+		{
+			name:    "missing domain",
+			domains: domains,
+			service: &openfunction.ServiceImpl{DomainRef: &openfunction.DomainReference{Name: "missing"}},
+			wantErr: true,
+		},
+		{
+			name:    "additional domains after the default domain without duplicates",
+			domains: domains,
+			service: &openfunction.ServiceImpl{
+				AdditionalDomainRefs: []openfunction.DomainReference{
+					{Name: "public", Namespace: "gateway"},
+					{Name: "openfunction"},
+					{Name: "public", Namespace: "gateway"},
+				},
+			},
+			want: []string{"ingress/openfunction", "gateway/public"},
+		},
+		{
+			name:    "additional domains after the referenced domain",
+			domains: domains,
+			service: &openfunction.ServiceImpl{
+				DomainRef:            &openfunction.DomainReference{Name: "public", Namespace: "ingress"},
+				AdditionalDomainRefs: []openfunction.DomainReference{{Name: "internal"}},
+			},
+			want: []string{"ingress/public", "gateway/internal"},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := openfunction.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var objs []client.Object
+			for i := range tt.domains {
+				objs = append(objs, tt.domains[i].DeepCopy())
+			}
+			r := &FunctionReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+				ctx:    context.Background(),
+			}
+
+			fn := &openfunction.Function{Spec: openfunction.FunctionSpec{Service: tt.service}}
+			got, err := r.selectDomains(fn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectDomains() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if keys := domainKeys(got); !reflect.DeepEqual(keys, tt.want) {
+				t.Errorf("selectDomains() = %v, want %v", keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomainIndexValues(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   *openfunction.Function
+		want []string
+	}{
+		{
+			name: "default domain",
+			fn:   &openfunction.Function{},
+			want: []string{defaultDomainKey},
+		},
+		{
+			name: "routed through the default domain",
+			fn: &openfunction.Function{
+				Status: openfunction.FunctionStatus{
+					Addresses: []openfunction.FunctionAddress{{Domain: "ingress/openfunction"}},
+				},
+			},
+			want: []string{"ingress/openfunction", defaultDomainKey},
+		},
+		{
+			name: "additional domains with the default domain",
+			fn: &openfunction.Function{
+				Spec: openfunction.FunctionSpec{
+					Service: &openfunction.ServiceImpl{
+						AdditionalDomainRefs: []openfunction.DomainReference{{Name: "public", Namespace: "ingress"}},
+					},
+				},
+			},
+			want: []string{defaultDomainKey, "ingress/public"},
+		},
+		{
+			name: "referenced domains",
+			fn: &openfunction.Function{
+				Spec: openfunction.FunctionSpec{
+					Service: &openfunction.ServiceImpl{
+						DomainRef:            &openfunction.DomainReference{Name: "internal"},
+						AdditionalDomainRefs: []openfunction.DomainReference{{Name: "public", Namespace: "ingress"}},
+					},
+				},
+				Status: openfunction.FunctionStatus{
+					Addresses: []openfunction.FunctionAddress{{Domain: "ingress/openfunction"}},
+				},
+			},
+			want: []string{"ingress/openfunction", "*/internal", "ingress/public"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := domainIndexValues(tt.fn); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("domainIndexValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := domainIndexValues(&openfunction.Domain{}); got != nil {
+		t.Errorf("domainIndexValues() of a domain = %v, want nil", got)
+	}
+}
+
+func TestFunctionURL(t *testing.T) {
+	fn := &openfunction.Function{ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: "default"}}
+	service := openfunction.IngressControllerService{Name: "ingress-nginx", Namespace: "ingress-nginx"}
+
+	tests := []struct {
+		name string
+		spec openfunction.DomainSpec
+		want string
+	}{
+		{
+			name: "path routing",
+			spec: openfunction.DomainSpec{Ingress: openfunction.IngressConfig{Service: service}},
+			want: "http://openfunction.ingress/default/hello",
+		},
+		{
+			name: "path routing on a custom port",
+			spec: openfunction.DomainSpec{Ingress: openfunction.IngressConfig{Service: openfunction.IngressControllerService{Port: 8080}}},
+			want: "http://openfunction.ingress:8080/default/hello",
+		},
+		{
+			name: "host routing",
+			spec: openfunction.DomainSpec{Ingress: openfunction.IngressConfig{Service: service}, Routing: openfunction.HostRouting, Host: "example.com"},
+			want: "http://hello.default.example.com",
+		},
+		{
+			name: "host routing over tls",
+			spec: openfunction.DomainSpec{
+				Ingress: openfunction.IngressConfig{
+					Service: openfunction.IngressControllerService{Port: 80, HTTPSPort: 443},
+					TLS:     &openfunction.IngressTLS{},
+				},
+				Routing: openfunction.HostRouting,
+				Host:    "example.com",
+			},
+			want: "https://hello.default.example.com",
+		},
+		{
+			name: "host routing through a gateway over tls on a custom port",
+			spec: openfunction.DomainSpec{
+				Gateway: &openfunction.GatewayConfig{
+					Service: openfunction.IngressControllerService{HTTPSPort: 8443},
+					TLS:     true,
+				},
+				Routing: openfunction.HostRouting,
+				Host:    "example.com",
+			},
+			want: "https://hello.default.example.com:8443",
+		},
+		{
+			name: "path routing through a gateway",
+			spec: openfunction.DomainSpec{
+				Gateway: &openfunction.GatewayConfig{Service: openfunction.IngressControllerService{Port: 80}},
+			},
+			want: "http://openfunction.ingress/default/hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain := &openfunction.Domain{ObjectMeta: metav1.ObjectMeta{Name: "openfunction", Namespace: "ingress"}, Spec: tt.spec}
+			if got := functionURL(fn, domain); got != tt.want {
+				t.Errorf("functionURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIngressName(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      *openfunction.Function
+		routing openfunction.RoutingMode
+		want    string
+	}{
+		{
+			name: "shared ingress",
+			fn:   &openfunction.Function{},
+			want: "openfunction-ingress-public",
+		},
+		{
+			name:    "host routing",
+			fn:      &openfunction.Function{},
+			routing: openfunction.HostRouting,
+			want:    "hello-ingress-public",
+		},
+		{
+			name: "standalone ingress",
+			fn: &openfunction.Function{
+				Spec: openfunction.FunctionSpec{Service: &openfunction.ServiceImpl{UseStandaloneIngress: true}},
+			},
+			want: "hello-ingress-public",
+		},
+		{
+			name: "service with a host header",
+			fn: &openfunction.Function{
+				Status: openfunction.FunctionStatus{Serving: &openfunction.Condition{ServiceHost: "hello.default"}},
+			},
+			want: "hello-ingress-public",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn.Name, tt.fn.Namespace = "hello", "default"
+			domain := &openfunction.Domain{
+				ObjectMeta: metav1.ObjectMeta{Name: "public", Namespace: "ingress"},
+				Spec:       openfunction.DomainSpec{Routing: tt.routing, Host: "example.com"},
+			}
+			if got := ingressName(tt.fn, domain); got != tt.want {
+				t.Errorf("ingressName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func newFunctionReconciler(t *testing.T, objs ...client.Object) *FunctionReconciler {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := openfunction.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	return &FunctionReconciler{
+		Client:       fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		Log:          logr.Discard(),
+		Scheme:       scheme,
+		Recorder:     record.NewFakeRecorder(100),
+		resolveTimes: &sync.Map{},
+		ctx:          context.Background(),
+	}
+}
+
+func newRevision(t *testing.T, revision int64, spec openfunction.ServingSpec) openfunction.Revision {
+	bs, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return openfunction.Revision{
+		Revision:    revision,
+		Image:       spec.Image,
+		ServingHash: util.Hash(spec),
+		ServingSpec: runtime.RawExtension{Raw: bs},
+	}
+}
+
+func revisionNumbers(revisions []openfunction.Revision) []int64 {
+	var numbers []int64
+	for _, r := range revisions {
+		numbers = append(numbers, r.Revision)
+	}
+
+	return numbers
+}
+
+func TestRecordRevision(t *testing.T) {
+	limit := func(n int32) *int32 { return &n }
+	rollbackTo := func(n int64) *int64 { return &n }
+	image := func(i int) openfunction.ServingSpec {
+		return openfunction.ServingSpec{Image: fmt.Sprintf("function:v%d", i)}
+	}
+
+	tests := []struct {
+		name       string
+		spec       openfunction.FunctionSpec
+		revisions  []int
+		serving    openfunction.ServingSpec
+		want       []int64
+		wantDigest string
+	}{
+		{
+			name:    "first revision",
+			serving: image(1),
+			want:    []int64{1},
+		},
+		{
+			name:       "new revision pinned by digest",
+			revisions:  []int{1},
+			serving:    openfunction.ServingSpec{Image: "function:v2@sha256:1234"},
+			want:       []int64{1, 2},
+			wantDigest: "sha256:1234",
+		},
+		{
+			name:      "serving which had run",
+			revisions: []int{1, 2},
+			serving:   image(1),
+			want:      []int64{1, 2},
+		},
+		{
+			name:      "oldest revisions are dropped",
+			spec:      openfunction.FunctionSpec{RevisionHistoryLimit: limit(2)},
+			revisions: []int{1, 2, 3},
+			serving:   image(4),
+			want:      []int64{3, 4},
+		},
+		{
+			name:      "the revision to roll back to is kept",
+			spec:      openfunction.FunctionSpec{RevisionHistoryLimit: limit(2), RollbackTo: rollbackTo(1)},
+			revisions: []int{1, 2},
+			serving:   image(3),
+			want:      []int64{1, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := &openfunction.Function{Spec: tt.spec}
+			for i, n := range tt.revisions {
+				fn.Status.Revisions = append(fn.Status.Revisions, newRevision(t, int64(i+1), image(n)))
+			}
+
+			r := newFunctionReconciler(t)
+			r.recordRevision(fn, &openfunction.Serving{Spec: tt.serving})
+			if got := revisionNumbers(fn.Status.Revisions); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("revisions = %v, want %v", got, tt.want)
+			}
+
+			for _, revision := range fn.Status.Revisions {
+				if revision.ServingHash != util.Hash(tt.serving) {
+					continue
+				}
+				if revision.Timestamp.IsZero() {
+					t.Errorf("timestamp of the running revision is not set")
+				}
+				if revision.Digest != tt.wantDigest {
+					t.Errorf("digest = %q, want %q", revision.Digest, tt.wantDigest)
+				}
+			}
+		})
+	}
+}
+
+func TestDesiredServingSpec(t *testing.T) {
+	rollbackTo := func(n int64) *int64 { return &n }
+	knative := openfunction.Knative
+
+	latest := openfunction.ServingSpec{Image: "function:v3", Runtime: &knative}
+	pinned := openfunction.ServingSpec{Image: "function:v1@sha256:1111", Runtime: &knative}
+	unpinned := openfunction.ServingSpec{Image: "function:v2", Runtime: &knative}
+	unpinnedRevision := newRevision(t, 2, unpinned)
+	unpinnedRevision.Digest = "sha256:2222"
+
+	tests := []struct {
+		name       string
+		rollbackTo *int64
+		want       openfunction.ServingSpec
+	}{
+		{
+			name: "latest spec",
+			want: latest,
+		},
+		{
+			name:       "spec of the revision",
+			rollbackTo: rollbackTo(1),
+			want:       pinned,
+		},
+		{
+			name:       "image of the revision is pinned by its digest",
+			rollbackTo: rollbackTo(2),
+			want:       openfunction.ServingSpec{Image: "function:v2@sha256:2222", Runtime: &knative},
+		},
+		{
+			name:       "latest spec if the revision is not found",
+			rollbackTo: rollbackTo(3),
+			want:       latest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := &openfunction.Function{
+				Spec: openfunction.FunctionSpec{
+					Image:      "function:v3",
+					Serving:    &openfunction.ServingImpl{},
+					RollbackTo: tt.rollbackTo,
+				},
+				Status: openfunction.FunctionStatus{
+					Revisions: []openfunction.Revision{newRevision(t, 1, pinned), unpinnedRevision},
+				},
+			}
+
+			r := newFunctionReconciler(t)
+			if got := r.desiredServingSpec(fn); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("desiredServingSpec() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNeedToCreateBuilderWhenRollingBack(t *testing.T) {
+	rollbackTo := int64(1)
+	fn := &openfunction.Function{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: "default"},
+		Spec: openfunction.FunctionSpec{
+			Image:      "function:v2",
+			Build:      &openfunction.BuildImpl{SrcRepo: &openfunction.GitRepo{Url: "https://github.com/openfunction/samples.git"}},
+			RollbackTo: &rollbackTo,
+		},
+		Status: openfunction.FunctionStatus{
+			Build:     &openfunction.Condition{ResourceHash: "built-from-v1", ResourceRef: "builder-v1", State: openfunction.Succeeded},
+			Revisions: []openfunction.Revision{newRevision(t, 1, openfunction.ServingSpec{Image: "function:v1"})},
+		},
+	}
+
+	r := newFunctionReconciler(t)
+	if r.needToCreateBuilder(fn) {
+		t.Errorf("needToCreateBuilder() = true while rolling back to a recorded revision, want false")
+	}
+
+	// The revision is not recorded, the function is built as usual.
+	fn.Spec.RollbackTo = nil
+	if !r.needToCreateBuilder(fn) {
+		t.Errorf("needToCreateBuilder() = false after the build changed, want true")
+	}
+}
+
+func TestAutoRollback(t *testing.T) {
+	labels := map[string]string{constants.FunctionLabel: "hello"}
+	stable := &openfunction.Serving{ObjectMeta: metav1.ObjectMeta{Name: "serving-stable", Namespace: "default", Labels: labels}}
+	failed := &openfunction.Serving{
+		ObjectMeta: metav1.ObjectMeta{Name: "serving-failed", Namespace: "default", Labels: labels},
+		Status:     openfunction.ServingStatus{State: openfunction.Failed},
+	}
+	fn := &openfunction.Function{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: "default"},
+		Spec: openfunction.FunctionSpec{
+			Serving: &openfunction.ServingImpl{AutoRollback: true},
+		},
+		Status: openfunction.FunctionStatus{
+			Serving: &openfunction.Condition{
+				ResourceRef:               failed.Name,
+				ResourceHash:              "failed-hash",
+				State:                     openfunction.Failed,
+				LastSuccessfulResourceRef: stable.Name,
+			},
+			Rollout: &openfunction.RolloutStatus{StableServing: stable.Name, CanaryServing: "serving-canary"},
+		},
+	}
+
+	r := newFunctionReconciler(t, fn.DeepCopy(), stable, failed)
+	stored := &openfunction.Function{}
+	if err := r.Get(context.Background(), client.ObjectKeyFromObject(fn), stored); err != nil {
+		t.Fatal(err)
+	}
+	fn.ResourceVersion = stored.ResourceVersion
+
+	if !r.needAutoRollback(fn, failed) {
+		t.Fatalf("needAutoRollback() = false, want true")
+	}
+	if err := r.autoRollback(fn, failed); err != nil {
+		t.Fatalf("autoRollback() error = %v", err)
+	}
+
+	if fn.Status.Serving.ResourceRef != stable.Name || fn.Status.Serving.State != openfunction.Running {
+		t.Errorf("serving = %s %s, want %s %s", fn.Status.Serving.ResourceRef, fn.Status.Serving.State, stable.Name, openfunction.Running)
+	}
+	if fn.Status.Serving.ResourceHash != "failed-hash" {
+		t.Errorf("resource hash = %s, the hash of the failed serving should be kept", fn.Status.Serving.ResourceHash)
+	}
+	if fn.Status.Rollout != nil {
+		t.Errorf("rollout = %+v, want nil", fn.Status.Rollout)
+	}
+	if rb := fn.Status.LastRollback; rb == nil || rb.FailedServing != failed.Name || rb.Serving != stable.Name || rb.Reason != openfunction.Failed {
+		t.Errorf("last rollback = %+v", rb)
+	}
+
+	servings := &openfunction.ServingList{}
+	if err := r.List(context.Background(), servings); err != nil {
+		t.Fatal(err)
+	}
+	if len(servings.Items) != 1 || servings.Items[0].Name != stable.Name {
+		t.Errorf("servings = %v, want only %s", servings.Items, stable.Name)
+	}
+
+	// Nothing to roll back to once the stable serving is the active one.
+	if r.needAutoRollback(fn, failed) {
+		t.Errorf("needAutoRollback() = true after rolled back, want false")
+	}
+}
+
+func TestProgressRollout(t *testing.T) {
+	hour := &metav1.Duration{Duration: time.Hour}
+	started := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	labels := map[string]string{constants.FunctionLabel: "hello"}
+
+	tests := []struct {
+		name           string
+		steps          []openfunction.RolloutStep
+		rollout        openfunction.RolloutStatus
+		servingTraffic *openfunction.ServingTraffic
+		wantStep       int32
+		wantWeight     int32
+		wantRequeue    bool
+		wantTraffic    *openfunction.ServingTraffic
+		wantDone       bool
+	}{
+		{
+			name:        "first step in pause",
+			steps:       []openfunction.RolloutStep{{Weight: 10, Pause: hour}, {Weight: 50, Pause: hour}},
+			rollout:     openfunction.RolloutStatus{Step: 0, Weight: 10, StepStartTime: metav1.Now()},
+			wantStep:    0,
+			wantWeight:  10,
+			wantRequeue: true,
+		},
+		{
+			name:        "move to the next step after the pause",
+			steps:       []openfunction.RolloutStep{{Weight: 10, Pause: hour}, {Weight: 50, Pause: hour}},
+			rollout:     openfunction.RolloutStatus{Step: 0, Weight: 10, StepStartTime: started},
+			wantStep:    1,
+			wantWeight:  50,
+			wantRequeue: true,
+		},
+		{
+			name:        "move to the next step with the same weight",
+			steps:       []openfunction.RolloutStep{{Weight: 20, Pause: hour}, {Weight: 20, Pause: hour}},
+			rollout:     openfunction.RolloutStatus{Step: 0, Weight: 20, StepStartTime: started},
+			wantStep:    1,
+			wantWeight:  20,
+			wantRequeue: true,
+		},
+		{
+			name:        "steps without pause are skipped",
+			steps:       []openfunction.RolloutStep{{Weight: 10}, {Weight: 30, Pause: hour}},
+			rollout:     openfunction.RolloutStatus{Step: 0, Weight: 10, StepStartTime: metav1.Now()},
+			wantStep:    1,
+			wantWeight:  30,
+			wantRequeue: true,
+		},
+		{
+			name:     "all steps done",
+			steps:    []openfunction.RolloutStep{{Weight: 10, Pause: hour}},
+			rollout:  openfunction.RolloutStatus{Step: 0, Weight: 10, StepStartTime: started},
+			wantDone: true,
+		},
+		{
+			name:           "canary serving splits the traffic of the step",
+			steps:          []openfunction.RolloutStep{{Weight: 10, Pause: hour}, {Weight: 50, Pause: hour}},
+			rollout:        openfunction.RolloutStatus{Step: 0, Weight: 10, StepStartTime: started, SplitByServing: true},
+			servingTraffic: &openfunction.ServingTraffic{StableServing: "serving-stable", CanaryWeight: 10},
+			wantStep:       1,
+			wantWeight:     50,
+			wantRequeue:    true,
+			wantTraffic:    &openfunction.ServingTraffic{StableServing: "serving-stable", CanaryWeight: 50},
+		},
+		{
+			name:           "wait for the canary serving to route all traffic to itself",
+			steps:          []openfunction.RolloutStep{{Weight: 10, Pause: hour}},
+			rollout:        openfunction.RolloutStatus{Step: 0, Weight: 10, StepStartTime: started, SplitByServing: true},
+			servingTraffic: &openfunction.ServingTraffic{StableServing: "serving-stable", CanaryWeight: 10},
+			wantStep:       1,
+			wantWeight:     100,
+		},
+		{
+			name:     "canary serving routes all traffic to itself",
+			steps:    []openfunction.RolloutStep{{Weight: 10, Pause: hour}},
+			rollout:  openfunction.RolloutStatus{Step: 1, Weight: 100, StepStartTime: started, SplitByServing: true},
+			wantDone: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rollout := tt.rollout
+			rollout.StableServing = "serving-stable"
+			rollout.StableService = "stable"
+			rollout.CanaryServing = "serving-canary"
+			rollout.CanaryService = "canary"
+			rollout.CanaryServicePort = 8080
+
+			fn := &openfunction.Function{
+				ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: "default"},
+				Spec: openfunction.FunctionSpec{
+					Serving: &openfunction.ServingImpl{Rollout: &openfunction.RolloutPolicy{Steps: tt.steps}},
+				},
+				Status: openfunction.FunctionStatus{
+					Serving: &openfunction.Condition{
+						ResourceRef:               "serving-canary",
+						State:                     openfunction.Running,
+						LastSuccessfulResourceRef: "serving-stable",
+						Service:                   "stable",
+					},
+					Rollout: &rollout,
+				},
+			}
+			stable := &openfunction.Serving{ObjectMeta: metav1.ObjectMeta{Name: "serving-stable", Namespace: "default", Labels: labels}}
+			canary := &openfunction.Serving{
+				ObjectMeta: metav1.ObjectMeta{Name: "serving-canary", Namespace: "default", Labels: labels},
+				Spec:       openfunction.ServingSpec{Traffic: tt.servingTraffic},
+				Status:     openfunction.ServingStatus{Traffic: tt.servingTraffic},
+			}
+
+			r := newFunctionReconciler(t, fn.DeepCopy(), stable, canary)
+			stored := &openfunction.Function{}
+			if err := r.Get(context.Background(), client.ObjectKeyFromObject(fn), stored); err != nil {
+				t.Fatal(err)
+			}
+			fn.ResourceVersion = stored.ResourceVersion
+
+			requeue, err := r.progressRollout(fn)
+			if err != nil {
+				t.Fatalf("progressRollout() error = %v", err)
+			}
+			if (requeue > 0) != tt.wantRequeue {
+				t.Errorf("requeue after = %s, want requeue %v", requeue, tt.wantRequeue)
+			}
+
+			// The progress must be persisted, not only kept in memory.
+			stored = &openfunction.Function{}
+			if err := r.Get(context.Background(), client.ObjectKeyFromObject(fn), stored); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantDone {
+				if stored.Status.Rollout != nil {
+					t.Fatalf("rollout = %+v, want nil", stored.Status.Rollout)
+				}
+				if s := stored.Status.Serving; s.LastSuccessfulResourceRef != "serving-canary" || s.Service != "canary" || s.ServicePort != 8080 {
+					t.Errorf("serving = %+v, want the canary serving and its service port", s)
+				}
+				return
+			}
+
+			got := stored.Status.Rollout
+			if got == nil {
+				t.Fatalf("rollout = nil, want in progress")
+			}
+			if got.Step != tt.wantStep || got.Weight != tt.wantWeight {
+				t.Errorf("step = %d, weight = %d, want step = %d, weight = %d", got.Step, got.Weight, tt.wantStep, tt.wantWeight)
+			}
+			if stored.Status.Serving.LastSuccessfulResourceRef != "serving-stable" {
+				t.Errorf("stable serving = %s, want serving-stable", stored.Status.Serving.LastSuccessfulResourceRef)
+			}
+
+			serving := &openfunction.Serving{}
+			if err := r.Get(context.Background(), client.ObjectKeyFromObject(canary), serving); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(serving.Spec.Traffic, tt.wantTraffic) {
+				t.Errorf("serving traffic = %+v, want %+v", serving.Spec.Traffic, tt.wantTraffic)
+			}
+		})
+	}
+}
+
+func TestHTTPRouteMatch(t *testing.T) {
+	fn := &openfunction.Function{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: "default"},
+		Status: openfunction.FunctionStatus{
+			Serving: &openfunction.Condition{Service: "serving-abcde"},
+		},
+	}
+	gateway := &openfunction.GatewayConfig{Name: "gateway", Namespace: "gateway"}
+
+	tests := []struct {
+		name          string
+		spec          openfunction.DomainSpec
+		wantPath      string
+		wantHostnames []gatewayv1alpha1.Hostname
+	}{
+		{
+			name:          "host routing",
+			spec:          openfunction.DomainSpec{Gateway: gateway, Routing: openfunction.HostRouting, Host: "example.com"},
+			wantPath:      "/",
+			wantHostnames: []gatewayv1alpha1.Hostname{"hello.default.example.com"},
+		},
+		{
+			name:          "path routing",
+			spec:          openfunction.DomainSpec{Gateway: gateway},
+			wantPath:      "/default/hello",
+			wantHostnames: []gatewayv1alpha1.Hostname{"openfunction.gateway"},
+		},
+		{
+			name:          "path routing with host",
+			spec:          openfunction.DomainSpec{Gateway: gateway, Routing: openfunction.PathRouting, Host: "example.com"},
+			wantPath:      "/default/hello",
+			wantHostnames: []gatewayv1alpha1.Hostname{"openfunction.gateway", "example.com"},
+		},
+	}
+
+	r := newFunctionReconciler(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain := &openfunction.Domain{ObjectMeta: metav1.ObjectMeta{Name: "openfunction", Namespace: "gateway"}, Spec: tt.spec}
+			route := &gatewayv1alpha1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Name: httpRouteName(fn, domain), Namespace: fn.Namespace}}
+			if err := r.mutateHTTPRoute(fn, domain, route)(); err != nil {
+				t.Fatalf("mutateHTTPRoute() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(route.Spec.Hostnames, tt.wantHostnames) {
+				t.Errorf("Hostnames = %v, want %v", route.Spec.Hostnames, tt.wantHostnames)
+			}
+			match := route.Spec.Rules[0].Matches[0]
+			if *match.Path.Type != gatewayv1alpha1.PathMatchPrefix || *match.Path.Value != tt.wantPath {
+				t.Errorf("Path = %s %s, want Prefix %s", *match.Path.Type, *match.Path.Value, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestHTTPRouteForwardTo(t *testing.T) {
+	type backend struct {
+		service string
+		port    int32
+		weight  int32
+	}
+
+	tests := []struct {
+		name    string
+		rollout *openfunction.RolloutStatus
+		want    []backend
+	}{
+		{
+			name: "no rollout",
+			want: []backend{{"stable", 8080, 100}},
+		},
+		{
+			name:    "split by the route",
+			rollout: &openfunction.RolloutStatus{CanaryService: "canary", CanaryServicePort: 9090, Weight: 30},
+			want:    []backend{{"stable", 8080, 70}, {"canary", 9090, 30}},
+		},
+		{
+			name:    "canary port defaults to 80",
+			rollout: &openfunction.RolloutStatus{CanaryService: "canary", Weight: 30},
+			want:    []backend{{"stable", 8080, 70}, {"canary", 80, 30}},
+		},
+		{
+			name:    "split by the canary serving",
+			rollout: &openfunction.RolloutStatus{CanaryService: "canary", CanaryServicePort: 9090, Weight: 30, SplitByServing: true},
+			want:    []backend{{"canary", 9090, 100}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := &openfunction.Function{
+				Status: openfunction.FunctionStatus{
+					Serving: &openfunction.Condition{Service: "stable", ServicePort: 8080},
+					Rollout: tt.rollout,
+				},
+			}
+
+			var got []backend
+			for _, f := range httpRouteForwardTo(fn) {
+				got = append(got, backend{*f.ServiceName, int32(*f.Port), *f.Weight})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("httpRouteForwardTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// The functions created before the engines and runtimes were added,
+// the hashes of their specs are computed by the first release.
+func hashStabilityFunctions() []*openfunction.Function {
+	version := "v1.0.0"
+	port := int32(8080)
+	revision := "main"
+	subPath := "hello-world"
+	builder := "openfunction/builder-go:latest"
+	asyncRuntime := openfunction.OpenFuncAsync
+
+	return []*openfunction.Function{
+		{
+			Spec: openfunction.FunctionSpec{
+				Image: "openfunction/sample-go-func:latest",
+				Build: &openfunction.BuildImpl{
+					SrcRepo: &openfunction.GitRepo{Url: "https://github.com/OpenFunction/samples.git"},
+				},
+				Serving: &openfunction.ServingImpl{},
+			},
+		},
+		{
+			Spec: openfunction.FunctionSpec{
+				Version:          &version,
+				Image:            "openfunction/sample-go-func:v1",
+				ImageCredentials: &corev1.LocalObjectReference{Name: "push-secret"},
+				Port:             &port,
+				Build: &openfunction.BuildImpl{
+					Builder: &builder,
+					Env:     map[string]string{"FUNC_NAME": "HelloWorld"},
+					Params:  map[string]string{"RUN_IMAGE": "openfunction/run:latest"},
+					SrcRepo: &openfunction.GitRepo{
+						Url:           "https://github.com/OpenFunction/samples.git",
+						Revision:      &revision,
+						SourceSubPath: &subPath,
+					},
+					Timeout: &metav1.Duration{Duration: 10 * time.Minute},
+				},
+				Serving: &openfunction.ServingImpl{
+					Runtime: &asyncRuntime,
+					Params:  map[string]string{"FUNC_CONTEXT": "{}"},
+					Timeout: &metav1.Duration{Duration: 5 * time.Minute},
+					Template: &corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "function", ImagePullPolicy: corev1.PullAlways}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestResolveSource(t *testing.T) {
+	commit := "1111111111111111111111111111111111111111"
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		line := commit + " refs/heads/main\n"
+		fmt.Fprintf(w, "%04x%s0000", len(line)+4, line)
+	}))
+	defer server.Close()
+
+	revision := "main"
+	fn := &openfunction.Function{
+		ObjectMeta: metav1.ObjectMeta{Name: "function", Namespace: "default"},
+		Spec: openfunction.FunctionSpec{
+			Build: &openfunction.BuildImpl{
+				SrcRepo: &openfunction.GitRepo{
+					Url:         server.URL,
+					Revision:    &revision,
+					AutoRebuild: &openfunction.AutoRebuild{Interval: &metav1.Duration{Duration: time.Hour}},
+				},
+			},
+		},
+	}
+	r := newFunctionReconciler(t, fn)
+
+	resolve := func() *openfunction.Function {
+		got := &openfunction.Function{}
+		if err := r.Get(r.ctx, client.ObjectKeyFromObject(fn), got); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.resolveSource(got); err != nil {
+			t.Fatalf("resolveSource() error = %v", err)
+		}
+		stored := &openfunction.Function{}
+		if err := r.Get(r.ctx, client.ObjectKeyFromObject(fn), stored); err != nil {
+			t.Fatal(err)
+		}
+		return stored
+	}
+
+	got := resolve()
+	if got.Status.Source == nil || got.Status.Source.Commit != commit || got.Status.Source.Url != server.URL {
+		t.Fatalf("Source = %+v, want commit %s of %s", got.Status.Source, commit, server.URL)
+	}
+
+	// The revision is not resolved again within the interval.
+	resolve()
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+
+	// The status is not written if the commit resolved does not change.
+	r.resolveTimes = &sync.Map{}
+	got.Status.Source.LastResolveTime = &metav1.Time{Time: time.Now().Add(-2 * time.Hour)}
+	if err := r.Status().Update(r.ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	version := got.ResourceVersion
+	if got = resolve(); got.ResourceVersion != version {
+		t.Errorf("ResourceVersion = %s, want %s", got.ResourceVersion, version)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+
+	// The new commit is recorded once the revision moves.
+	commit = "2222222222222222222222222222222222222222"
+	r.resolveTimes = &sync.Map{}
+	got.Status.Source.LastResolveTime = &metav1.Time{Time: time.Now().Add(-2 * time.Hour)}
+	if err := r.Status().Update(r.ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	if got = resolve(); got.Status.Source.Commit != commit {
+		t.Errorf("Commit = %s, want %s", got.Status.Source.Commit, commit)
+	}
+}
+
+func TestBuilderSpecHash(t *testing.T) {
+	want := []string{"5698763511241576889", "5764059638025840385"}
+
+	r := &FunctionReconciler{}
+	for i, fn := range hashStabilityFunctions() {
+		if got := util.Hash(r.createBuilderSpec(fn)); got != want[i] {
+			t.Errorf("hash of builder spec %d = %s, want %s", i, got, want[i])
+		}
+	}
+
+	if got, want := util.Hash(openfunction.BuilderSpec{}), "4627449290926469380"; got != want {
+		t.Errorf("hash of empty builder spec = %s, want %s", got, want)
+	}
+}
+
+func TestBuilderSpecHashWithEngine(t *testing.T) {
+	r := &FunctionReconciler{}
+	fn := hashStabilityFunctions()[0]
+	base := util.Hash(r.createBuilderSpec(fn))
+
+	kaniko := openfunction.Kaniko
+	fn.Spec.Build.Engine = &kaniko
+	withEngine := util.Hash(r.createBuilderSpec(fn))
+	if withEngine == base {
+		t.Errorf("hash does not change when the engine is set")
+	}
+
+	image := "gcr.io/kaniko-project/executor:latest"
+	fn.Spec.Build.Kaniko = &openfunction.KanikoEngine{Image: &image}
+	if got := util.Hash(r.createBuilderSpec(fn)); got == withEngine {
+		t.Errorf("hash does not change when the Kaniko parameters are set")
+	}
+}
+
+func TestServingSpecHash(t *testing.T) {
+	want := []string{"8995025440563615553", "1734812679528392192"}
+
+	r := newFunctionReconciler(t)
+	for i, fn := range hashStabilityFunctions() {
+		if got := util.Hash(r.desiredServingSpec(fn)); got != want[i] {
+			t.Errorf("hash of serving spec %d = %s, want %s", i, got, want[i])
+		}
+	}
+
+	// The hash of the functions without serving.
+	if got, want := util.Hash(openfunction.ServingSpec{}), "4475998801420000305"; got != want {
+		t.Errorf("hash of empty serving spec = %s, want %s", got, want)
+	}
+}
+
+func TestServingSpecHashWithRuntime(t *testing.T) {
+	r := newFunctionReconciler(t)
+	fn := hashStabilityFunctions()[0]
+	base := util.Hash(r.desiredServingSpec(fn))
+
+	replicas := int32(2)
+	fn.Spec.Serving.Plain = &openfunction.PlainRuntime{Replicas: &replicas}
+	withPlain := util.Hash(r.desiredServingSpec(fn))
+	if withPlain == base {
+		t.Errorf("hash does not change when the Plain parameters are set")
+	}
+
+	fn.Spec.Serving.KedaHTTP = &openfunction.KedaHTTPRuntime{MaxReplicas: &replicas}
+	if got := util.Hash(r.desiredServingSpec(fn)); got == withPlain {
+		t.Errorf("hash does not change when the KedaHTTP parameters are set")
+	}
+}
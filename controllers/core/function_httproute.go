@@ -0,0 +1,176 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	gatewayv1alpha1 "sigs.k8s.io/gateway-api/apis/v1alpha1"
+
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+	"github.com/openfunction/pkg/constants"
+	"github.com/openfunction/pkg/util"
+)
+
+// Each function has its own HTTPRoute on each domain which uses a Gateway.
+func httpRouteName(fn *openfunction.Function, domain *openfunction.Domain) string {
+	return fmt.Sprintf("%s-%s-%s", fn.Name, domain.Namespace, domain.Name)
+}
+
+func (r *FunctionReconciler) createOrUpdateHTTPRoute(fn *openfunction.Function, domain *openfunction.Domain) (controllerutil.OperationResult, error) {
+
+	route := &gatewayv1alpha1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      httpRouteName(fn, domain),
+			Namespace: fn.Namespace,
+		},
+	}
+
+	return controllerutil.CreateOrUpdate(r.ctx, r.Client, route, r.mutateHTTPRoute(fn, domain, route))
+}
+
+// The HTTPRoute matches the host of the function in `Host` routing mode, or the path `/{namespace}/{name}`
+// on the hosts of the domain in `Path` routing mode, and splits the traffic between the stable
+// and the canary serving when a rollout is in progress.
+// The HTTPRoute can not strip the path prefix, so the function receives the whole path in `Path` routing mode.
+func (r *FunctionReconciler) mutateHTTPRoute(fn *openfunction.Function, domain *openfunction.Domain, route *gatewayv1alpha1.HTTPRoute) controllerutil.MutateFn {
+
+	return func() error {
+		allow := gatewayv1alpha1.GatewayAllowFromList
+		pathType := gatewayv1alpha1.PathMatchPrefix
+		path := "/"
+		hostnames := []gatewayv1alpha1.Hostname{gatewayv1alpha1.Hostname(domain.FunctionHost(fn.Name, fn.Namespace))}
+		if !domain.IsHostRouting() {
+			path = fmt.Sprintf("/%s/%s", fn.Namespace, fn.Name)
+			hostnames = nil
+			for _, host := range domainHosts(domain) {
+				hostnames = append(hostnames, gatewayv1alpha1.Hostname(host))
+			}
+		}
+
+		// The service routes the requests by host, such as the KEDA HTTP interceptor.
+		var filters []gatewayv1alpha1.HTTPRouteFilter
+		if host := fn.Status.Serving.ServiceHost; host != "" {
+			filters = []gatewayv1alpha1.HTTPRouteFilter{
+				{
+					Type: gatewayv1alpha1.HTTPRouteFilterRequestHeaderModifier,
+					RequestHeaderModifier: &gatewayv1alpha1.HTTPRequestHeaderFilter{
+						Set: map[string]string{"Host": host},
+					},
+				},
+			}
+		}
+
+		route.Spec = gatewayv1alpha1.HTTPRouteSpec{
+			Gateways: &gatewayv1alpha1.RouteGateways{
+				Allow: &allow,
+				GatewayRefs: []gatewayv1alpha1.GatewayReference{
+					{
+						Name:      domain.Spec.Gateway.Name,
+						Namespace: domain.Spec.Gateway.Namespace,
+					},
+				},
+			},
+			Hostnames: hostnames,
+			Rules: []gatewayv1alpha1.HTTPRouteRule{
+				{
+					Matches: []gatewayv1alpha1.HTTPRouteMatch{
+						{
+							Path: &gatewayv1alpha1.HTTPPathMatch{
+								Type:  &pathType,
+								Value: &path,
+							},
+						},
+					},
+					Filters:   filters,
+					ForwardTo: httpRouteForwardTo(fn),
+				},
+			},
+		}
+
+		if route.Labels == nil {
+			route.Labels = make(map[string]string)
+		}
+		route.Labels[constants.FunctionLabel] = fn.Name
+		route.Labels[constants.DomainLabel] = domain.Name
+		route.Labels[constants.DomainNamespaceLabel] = domain.Namespace
+
+		return controllerutil.SetControllerReference(fn, route, r.Scheme)
+	}
+}
+
+func httpRouteForwardTo(fn *openfunction.Function) []gatewayv1alpha1.HTTPRouteForwardTo {
+	service, servicePort := routedService(fn)
+	port := gatewayv1alpha1.PortNumber(servicePort)
+	weight := int32(100)
+
+	if !splitByRoute(fn) {
+		return []gatewayv1alpha1.HTTPRouteForwardTo{
+			{
+				ServiceName: &service,
+				Port:        &port,
+				Weight:      &weight,
+			},
+		}
+	}
+
+	canaryService := fn.Status.Rollout.CanaryService
+	canaryPort := gatewayv1alpha1.PortNumber(canaryServicePort(fn))
+	canaryWeight := fn.Status.Rollout.Weight
+	weight = 100 - canaryWeight
+	return []gatewayv1alpha1.HTTPRouteForwardTo{
+		{
+			ServiceName: &service,
+			Port:        &port,
+			Weight:      &weight,
+		},
+		{
+			ServiceName: &canaryService,
+			Port:        &canaryPort,
+			Weight:      &canaryWeight,
+		},
+	}
+}
+
+// Delete the HTTPRoutes of the function except the desired ones.
+// Nothing need to be cleaned if the Gateway API is not installed.
+func (r *FunctionReconciler) cleanHTTPRoute(fn *openfunction.Function, desired map[string]bool) error {
+	routes := &gatewayv1alpha1.HTTPRouteList{}
+	if err := r.List(r.ctx, routes, client.InNamespace(fn.Namespace), client.MatchingLabels{constants.FunctionLabel: fn.Name}); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+
+	for i := range routes.Items {
+		route := &routes.Items[i]
+		if desired[route.Name] || !metav1.IsControlledBy(route, fn) {
+			continue
+		}
+
+		if err := r.Delete(r.ctx, route); util.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	return nil
+}
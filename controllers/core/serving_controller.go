@@ -19,37 +19,41 @@ package core
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	openfunction "github.com/openfunction/apis/core/v1alpha2"
 	"github.com/openfunction/pkg/core"
-	"github.com/openfunction/pkg/core/serving/knative"
-	"github.com/openfunction/pkg/core/serving/openfuncasync"
+	"github.com/openfunction/pkg/metrics"
 	"github.com/openfunction/pkg/util"
 )
 
 // ServingReconciler reconciles a Serving object
 type ServingReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
-	ctx    context.Context
-	timers map[string]*time.Timer
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	ctx      context.Context
 }
 
 func NewServingReconciler(mgr manager.Manager) *ServingReconciler {
 
 	r := &ServingReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Serving"),
-		timers: make(map[string]*time.Timer),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Log:      ctrl.Log.WithName("controllers").WithName("Serving"),
+		Recorder: mgr.GetEventRecorderFor("serving-controller"),
 	}
 
 	return r
@@ -61,8 +65,12 @@ func NewServingReconciler(mgr manager.Manager) *ServingReconciler {
 //+kubebuilder:rbac:groups=serving.knative.dev,resources=services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=dapr.io,resources=components;subscriptions,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=keda.sh,resources=scaledjobs;scaledobjects,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=http.keda.sh,resources=httpscaledobjects,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -74,7 +82,10 @@ func NewServingReconciler(mgr manager.Manager) *ServingReconciler {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.8.3/pkg/reconcile
 func (r *ServingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	r.ctx = ctx
+	// Work on a copy of the reconciler, so that the concurrent reconciles do not share the context.
+	rc := *r
+	rc.ctx = ctx
+	r = &rc
 	log := r.Log.WithValues("Serving", req.NamespacedName)
 
 	var s openfunction.Serving
@@ -82,7 +93,6 @@ func (r *ServingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	if err := r.Get(ctx, req.NamespacedName, &s); err != nil {
 		if util.IsNotFound(err) {
 			log.V(1).Info("Serving deleted")
-			r.stopTimer(req.NamespacedName.String())
 		}
 		return ctrl.Result{}, util.IgnoreNotFound(err)
 	}
@@ -92,29 +102,34 @@ func (r *ServingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		log.Error(nil, "Unknown runtime", "runtime", *s.Spec.Runtime)
 		s.Status.Phase = openfunction.ServingPhase
 		s.Status.State = openfunction.UnknownRuntime
-		if err := r.Status().Update(r.ctx, &s); err != nil {
+		if err := r.updateStatus(&s); err != nil {
 			log.Error(err, "Failed to update serving status")
 			return ctrl.Result{}, err
 		}
+		r.Recorder.Eventf(&s, corev1.EventTypeWarning, UnknownRuntime, "Unknown runtime %s", *s.Spec.Runtime)
 		return ctrl.Result{}, nil
 	}
 
 	// Serving start timeout, update serving status.
-	if s.Spec.Timeout != nil &&
-		time.Since(s.CreationTimestamp.Time) > s.Spec.Timeout.Duration {
-		if s.Status.IsStarting() {
-			s.Status.State = openfunction.Timeout
-			if err := r.Status().Update(r.ctx, &s); err != nil {
-				log.Error(err, "Failed to update serving status")
-				return ctrl.Result{}, err
-			}
+	// The deadline is computed from the start time persisted in the status, so it survives the restart of the operator.
+	// The deadline only applies before the serving is running, a running serving keeps being reconciled,
+	// so that it still applies traffic changes and reports later failures.
+	deadline, hasDeadline := servingDeadline(&s)
+	if hasDeadline && s.Status.IsStarting() && !time.Now().Before(deadline) {
+		s.Status.Phase = openfunction.ServingPhase
+		s.Status.State = openfunction.Timeout
+		if err := r.updateStatus(&s); err != nil {
+			log.Error(err, "Failed to update serving status")
+			return ctrl.Result{}, err
 		}
+		metrics.IncTimeout(metrics.KindServing)
+		r.Recorder.Eventf(&s, corev1.EventTypeWarning, ServingTimeout, "Serving did not run within %s", s.Spec.Timeout.Duration)
 		return ctrl.Result{}, nil
 	}
 
-	// Start timer if serving is starting.
-	if s.Status.IsStarting() {
-		r.startTimer(&s)
+	// The serving which timed out is not watched any more.
+	if s.Status.State == openfunction.Timeout {
+		return ctrl.Result{}, nil
 	}
 
 	// Serving is running, no need to create.
@@ -123,61 +138,54 @@ func (r *ServingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		if err := r.getServingResult(&s, servingRun); err != nil {
 			return ctrl.Result{}, err
 		}
-		return ctrl.Result{}, nil
-	}
 
-	if s.Spec.Timeout != nil &&
-		time.Since(s.CreationTimestamp.Time) > s.Spec.Timeout.Duration {
-		log.Error(nil, "Serving start timeout")
-
-		if err := servingRun.Clean(&s); err != nil {
-			log.Error(err, "Failed to clean serving")
+		if err := r.splitTraffic(&s, servingRun); err != nil {
 			return ctrl.Result{}, err
 		}
 
-		s.Status.Phase = openfunction.ServingPhase
-		s.Status.State = openfunction.Timeout
-		if err := r.Status().Update(r.ctx, &s); err != nil {
-			log.Error(err, "Failed to update serving status")
-			return ctrl.Result{}, err
+		// Reconcile again at the deadline, so that the serving times out even if nothing else changes.
+		if hasDeadline && s.Status.IsStarting() {
+			return ctrl.Result{RequeueAfter: time.Until(deadline)}, nil
 		}
 		return ctrl.Result{}, nil
 	}
 
-	// Reset serving status.
-	s.Status = openfunction.ServingStatus{}
-	if err := r.Status().Update(r.ctx, &s); err != nil {
+	// Reset serving status, the timeout is counted from the first attempt to start the serving.
+	start := metav1.Now()
+	if s.Status.StartTime != nil {
+		start = *s.Status.StartTime
+	}
+	s.Status = openfunction.ServingStatus{StartTime: &start}
+	if err := r.updateStatus(&s); err != nil {
 		log.Error(err, "Failed to reset serving status")
 		return ctrl.Result{}, err
 	}
 
 	if err := servingRun.Run(&s); err != nil {
 		log.Error(err, "Failed to start serving")
+		r.Recorder.Eventf(&s, corev1.EventTypeWarning, ServingStartFailed, "Failed to start serving: %s", err)
 		return ctrl.Result{}, err
 	}
 
 	s.Status.Phase = openfunction.ServingPhase
 	s.Status.State = openfunction.Starting
-	if err := r.Status().Update(r.ctx, &s); err != nil {
+	if err := r.updateStatus(&s); err != nil {
 		log.Error(err, "Failed to update serving status")
 		return ctrl.Result{}, err
 	}
 
+	r.Recorder.Event(&s, corev1.EventTypeNormal, ServingStarting, "Serving is starting")
 	log.V(1).Info("Serving is starting")
 
+	if deadline, ok := servingDeadline(&s); ok {
+		return ctrl.Result{RequeueAfter: time.Until(deadline)}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
 func (r *ServingReconciler) getServingRun(s *openfunction.Serving) core.ServingRun {
 
-	switch *s.Spec.Runtime {
-	case openfunction.OpenFuncAsync:
-		return openfuncasync.NewServingRun(r.ctx, r.Client, r.Scheme, r.Log)
-	case openfunction.Knative:
-		return knative.NewServingRun(r.ctx, r.Client, r.Scheme, r.Log)
-	default:
-		return nil
-	}
+	return core.NewServingRun(string(*s.Spec.Runtime), r.ctx, r.Client, r.Scheme, r.Log)
 }
 
 // Update the status of the serving according to the result of the serving.
@@ -198,77 +206,81 @@ func (r *ServingReconciler) getServingResult(s *openfunction.Serving, servingRun
 
 	if res != s.Status.State {
 		s.Status.State = res
-		if err := r.Status().Update(r.ctx, s); err != nil {
+		if err := r.updateStatus(s); err != nil {
 			return err
 		}
 
-		r.stopTimer(fmt.Sprintf("%s/%s", s.Namespace, s.Name))
+		if res == openfunction.Running {
+			start := s.CreationTimestamp.Time
+			if s.Status.StartTime != nil {
+				start = s.Status.StartTime.Time
+			}
+			metrics.ObserveServingReady(string(*s.Spec.Runtime), time.Since(start))
+			r.Recorder.Event(s, corev1.EventTypeNormal, ServingRunning, "Serving is running")
+		} else {
+			r.Recorder.Eventf(s, corev1.EventTypeWarning, ServingFailed, "Serving failed: %s", res)
+		}
 		log.V(1).Info("Update serving status", "state", res)
 	}
 
 	return nil
 }
 
-func (r *ServingReconciler) startTimer(serving *openfunction.Serving) {
-	namespacedName := fmt.Sprintf("%s/%s", serving.Namespace, serving.Name)
-	log := r.Log.WithName("Timer").WithValues("Serving", namespacedName)
+// Apply the traffic split of the running serving if the runtime splits the traffic itself,
+// the status records the traffic split once it is in effect.
+func (r *ServingReconciler) splitTraffic(s *openfunction.Serving, servingRun core.ServingRun) error {
+	log := r.Log.WithName("SplitTraffic").
+		WithValues("Serving", fmt.Sprintf("%s/%s", s.Namespace, s.Name))
 
-	if serving.Spec.Timeout == nil ||
-		time.Since(serving.CreationTimestamp.Time) > serving.Spec.Timeout.Duration {
-		return
+	splitter, ok := servingRun.(core.TrafficSplitter)
+	if !ok || s.Status.State != openfunction.Running || reflect.DeepEqual(s.Spec.Traffic, s.Status.Traffic) {
+		return nil
 	}
 
-	// Skipped when timer had started.
-	t, ok := r.timers[namespacedName]
-	if ok {
-		return
+	applied, err := splitter.SplitTraffic(s)
+	if err != nil {
+		log.Error(err, "Failed to split traffic")
+		return err
 	}
 
-	t = time.NewTimer(serving.Spec.Timeout.Duration - time.Since(serving.CreationTimestamp.Time))
-	r.timers[namespacedName] = t
-
-	go func() {
+	// The serving is reconciled again when the resources of the runtime change.
+	if !applied {
+		return nil
+	}
 
-		defer r.stopTimer(namespacedName)
+	s.Status.Traffic = s.Spec.Traffic.DeepCopy()
+	if err := r.updateStatus(s); err != nil {
+		log.Error(err, "Failed to update serving status")
+		return err
+	}
 
-		select {
-		case <-t.C:
-			s := &openfunction.Serving{}
-			if err := r.Get(r.ctx, client.ObjectKeyFromObject(serving), s); err != nil {
-				if util.IsNotFound(err) {
-					log.Info("Serving had delete")
-					return
-				}
+	log.V(1).Info("Traffic split applied")
+	return nil
+}
 
-				log.Error(err, "Failed to get Serving")
-				return
-			}
+// The deadline of the serving, the servings started before the start time was recorded count from their creation.
+func servingDeadline(s *openfunction.Serving) (time.Time, bool) {
+	if s.Spec.Timeout == nil {
+		return time.Time{}, false
+	}
 
-			if s.Status.IsStarting() {
-				log.Error(nil, "Serving start timeout")
-				s.Status.State = openfunction.Timeout
-				if err := r.Status().Update(r.ctx, s); err != nil {
-					log.Error(err, "Failed to update serving status")
-				}
-			}
-		}
-	}()
+	start := s.CreationTimestamp.Time
+	if s.Status.StartTime != nil {
+		start = s.Status.StartTime.Time
+	}
 
-	log.V(1).Info("Timer started")
+	return start.Add(s.Spec.Timeout.Duration), true
 }
 
-func (r *ServingReconciler) stopTimer(key string) {
-	log := r.Log.WithName("Timer").WithValues("Serving", key)
-	t := r.timers[key]
-	if t != nil {
-		t.Stop()
-		delete(r.timers, key)
-		log.Info("Timer stopped")
-	}
+// Update the status of the serving, the conditions are synced with the serving state.
+func (r *ServingReconciler) updateStatus(s *openfunction.Serving) error {
+	s.Status.ObservedGeneration = s.Generation
+	s.Status.SyncConditions(s.Generation)
+	return r.Status().Update(r.ctx, s)
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *ServingReconciler) SetupWithManager(mgr ctrl.Manager, owns []client.Object) error {
+func (r *ServingReconciler) SetupWithManager(mgr ctrl.Manager, owns []client.Object, opts controller.Options) error {
 
 	b := ctrl.NewControllerManagedBy(mgr).
 		For(&openfunction.Serving{})
@@ -277,5 +289,5 @@ func (r *ServingReconciler) SetupWithManager(mgr ctrl.Manager, owns []client.Obj
 		b.Owns(own)
 	}
 
-	return b.Complete(r)
+	return b.WithOptions(opts).Complete(r)
 }
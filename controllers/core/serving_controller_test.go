@@ -0,0 +1,181 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+	"github.com/openfunction/pkg/core"
+)
+
+func TestServingDeadline(t *testing.T) {
+	created := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	started := created.Add(time.Minute)
+
+	tests := []struct {
+		name    string
+		timeout *metav1.Duration
+		start   *metav1.Time
+		want    time.Time
+		wantOK  bool
+	}{
+		{
+			name: "no timeout",
+		},
+		{
+			name:    "counts from the creation if not started",
+			timeout: &metav1.Duration{Duration: 10 * time.Minute},
+			want:    created.Add(10 * time.Minute),
+			wantOK:  true,
+		},
+		{
+			name:    "counts from the start time",
+			timeout: &metav1.Duration{Duration: 10 * time.Minute},
+			start:   &metav1.Time{Time: started},
+			want:    started.Add(10 * time.Minute),
+			wantOK:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &openfunction.Serving{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.Time{Time: created}},
+				Spec:       openfunction.ServingSpec{Timeout: tt.timeout},
+				Status:     openfunction.ServingStatus{StartTime: tt.start},
+			}
+
+			got, ok := servingDeadline(s)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("servingDeadline() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+const fakeRuntime = "Fake"
+
+// The result the fake runtime reports for the servings.
+var fakeResult string
+
+type fakeServingRun struct{}
+
+func (*fakeServingRun) Run(s *openfunction.Serving) error { return nil }
+
+func (*fakeServingRun) Result(s *openfunction.Serving) (string, error) { return fakeResult, nil }
+
+func (*fakeServingRun) Clean(s *openfunction.Serving) error { return nil }
+
+func (*fakeServingRun) SplitTraffic(s *openfunction.Serving) (bool, error) { return true, nil }
+
+func init() {
+	core.RegisterServingRun(fakeRuntime, func(ctx context.Context, c client.Client, scheme *runtime.Scheme, log logr.Logger) core.ServingRun {
+		return &fakeServingRun{}
+	})
+}
+
+func TestReconcileServingPastDeadline(t *testing.T) {
+	rt := openfunction.Runtime(fakeRuntime)
+	traffic := &openfunction.ServingTraffic{StableServing: "serving-stable", CanaryWeight: 50}
+
+	tests := []struct {
+		name        string
+		state       string
+		result      string
+		wantState   string
+		wantTraffic *openfunction.ServingTraffic
+	}{
+		{
+			name:        "running serving applies the traffic",
+			state:       openfunction.Running,
+			result:      openfunction.Running,
+			wantState:   openfunction.Running,
+			wantTraffic: traffic,
+		},
+		{
+			name:      "running serving reports the failure",
+			state:     openfunction.Running,
+			result:    openfunction.Failed,
+			wantState: openfunction.Failed,
+		},
+		{
+			name:      "starting serving times out",
+			state:     openfunction.Starting,
+			result:    openfunction.Running,
+			wantState: openfunction.Timeout,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := openfunction.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeResult = tt.result
+			started := metav1.NewTime(time.Now().Add(-time.Hour))
+			s := &openfunction.Serving{
+				ObjectMeta: metav1.ObjectMeta{Name: "serving-canary", Namespace: "default"},
+				Spec: openfunction.ServingSpec{
+					Runtime: &rt,
+					Timeout: &metav1.Duration{Duration: time.Minute},
+					Traffic: traffic,
+				},
+				Status: openfunction.ServingStatus{
+					Phase:     openfunction.ServingPhase,
+					State:     tt.state,
+					StartTime: &started,
+					Traffic:   &openfunction.ServingTraffic{StableServing: "serving-stable", CanaryWeight: 10},
+				},
+			}
+
+			r := &ServingReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(s).Build(),
+				Log:      logr.Discard(),
+				Scheme:   scheme,
+				Recorder: record.NewFakeRecorder(10),
+			}
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(s)}); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+
+			got := &openfunction.Serving{}
+			if err := r.Get(context.Background(), client.ObjectKeyFromObject(s), got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status.State != tt.wantState {
+				t.Errorf("state = %s, want %s", got.Status.State, tt.wantState)
+			}
+			if tt.wantTraffic != nil && !reflect.DeepEqual(got.Status.Traffic, tt.wantTraffic) {
+				t.Errorf("traffic = %+v, want %+v", got.Status.Traffic, tt.wantTraffic)
+			}
+		})
+	}
+}
@@ -24,8 +24,6 @@ const (
 	EventBusNameLabel          = "eventbus-name"
 	EventBusTopicName          = "eventbus-topic-name"
 
-	DefaultLogLevel = "info"
-
 	// Component Name Template
 
 	// EventSourceComponentNameTmpl => esc(EventSource Component)-{eventSourceName}-{sourceKind}-{eventName}
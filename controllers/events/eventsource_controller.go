@@ -38,13 +38,10 @@ import (
 
 	ofcore "github.com/openfunction/apis/core/v1alpha2"
 	ofevent "github.com/openfunction/apis/events/v1alpha1"
+	"github.com/openfunction/pkg/config"
 	"github.com/openfunction/pkg/util"
 )
 
-const (
-	eventSourceHandlerImage = "openfunctiondev/eventsource-handler:v2"
-)
-
 // EventSourceReconciler reconciles a EventSource object
 type EventSourceReconciler struct {
 	client.Client
@@ -83,7 +80,7 @@ func (r *EventSourceReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 	eventSource := &ofevent.EventSource{}
 	r.EventSourceConfig = &EventSourceConfig{}
-	r.EventSourceConfig.LogLevel = DefaultLogLevel
+	r.EventSourceConfig.LogLevel = config.Get().LogLevel
 
 	if err := r.Get(ctx, req.NamespacedName, eventSource); err != nil {
 		log.V(1).Info("EventSource deleted", "error", err)
@@ -120,8 +117,8 @@ func (r *EventSourceReconciler) createOrUpdateEventSource(ctx context.Context, l
 		ofevent.Pending, metav1.ConditionUnknown, ofevent.PendingCreation,
 	).SetMessage("Identified EventSource creation signal"))
 
-	// Generate the eventsource function instance with image eventSourceHandlerImage.
-	r.Function = InitFunction(eventSourceHandlerImage)
+	// Generate the eventsource function instance with the configured handler image.
+	r.Function = InitFunction(config.Get().EventSourceHandlerImage)
 
 	if eventSource.Spec.EventBus == "" && eventSource.Spec.Sink == nil {
 		err := errors.New("must set spec.eventBus or spec.sink")
@@ -40,13 +40,10 @@ import (
 
 	ofcore "github.com/openfunction/apis/core/v1alpha2"
 	ofevent "github.com/openfunction/apis/events/v1alpha1"
+	"github.com/openfunction/pkg/config"
 	"github.com/openfunction/pkg/util"
 )
 
-const (
-	triggerHandlerImage = "openfunctiondev/trigger-handler:v2"
-)
-
 // TriggerReconciler reconciles a Trigger object
 type TriggerReconciler struct {
 	client.Client
@@ -95,15 +92,15 @@ func (r *TriggerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	trigger := &ofevent.Trigger{}
 	r.TriggerConfig = &TriggerConfig{}
 	r.TriggerConfig.Subscribers = map[string]*Subscriber{}
-	r.TriggerConfig.LogLevel = DefaultLogLevel
+	r.TriggerConfig.LogLevel = config.Get().LogLevel
 
 	if err := r.Get(ctx, req.NamespacedName, trigger); err != nil {
 		log.V(1).Info("Trigger deleted", "error", err)
 		return ctrl.Result{}, util.IgnoreNotFound(err)
 	}
 
-	// Generate the trigger function instance with image triggerHandlerImage.
-	r.Function = InitFunction(triggerHandlerImage)
+	// Generate the trigger function instance with the configured handler image.
+	r.Function = InitFunction(config.Get().TriggerHandlerImage)
 
 	if err := r.createOrUpdateTrigger(ctx, log, trigger); err != nil {
 		log.Error(err, "Failed to create or update trigger",
@@ -11,6 +11,7 @@ require (
 	github.com/mitchellh/hashstructure v1.1.0
 	github.com/onsi/ginkgo v1.16.4
 	github.com/onsi/gomega v1.15.0
+	github.com/prometheus/client_golang v1.11.0
 	github.com/shipwright-io/build v0.6.0
 	go.uber.org/zap v1.19.0
 	k8s.io/api v0.21.4
@@ -19,6 +20,7 @@ require (
 	knative.dev/eventing v0.26.0
 	knative.dev/serving v0.26.0
 	sigs.k8s.io/controller-runtime v0.9.7
+	sigs.k8s.io/gateway-api v0.3.0
 )
 
 replace (
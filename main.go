@@ -17,32 +17,49 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"strings"
+	"time"
 
 	componentsv1alpha1 "github.com/dapr/dapr/pkg/apis/components/v1alpha1"
 	subscriptionsv1alpha1 "github.com/dapr/dapr/pkg/apis/subscriptions/v1alpha1"
 	kedav1alpha1 "github.com/kedacore/keda/v2/api/v1alpha1"
 	shipwrightv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	"go.uber.org/zap/zapcore"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 
 	corev1alpha1 "github.com/openfunction/apis/core/v1alpha1"
 	corev1alpha2 "github.com/openfunction/apis/core/v1alpha2"
 	openfunctionevent "github.com/openfunction/apis/events/v1alpha1"
 	"github.com/openfunction/controllers/core"
 	eventcontrollers "github.com/openfunction/controllers/events"
-	"github.com/openfunction/pkg/core/builder"
-	"github.com/openfunction/pkg/core/serving"
+	"github.com/openfunction/pkg/config"
+	"github.com/openfunction/pkg/constants"
+	ofcore "github.com/openfunction/pkg/core"
+	_ "github.com/openfunction/pkg/core/builder"
+	_ "github.com/openfunction/pkg/core/serving"
+	"github.com/openfunction/pkg/metrics"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	kservingv1 "knative.dev/serving/pkg/apis/serving/v1"
 	knserving "knative.dev/serving/pkg/client/clientset/versioned/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	gatewayv1alpha1 "sigs.k8s.io/gateway-api/apis/v1alpha1"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -61,6 +78,7 @@ func init() {
 	_ = kedav1alpha1.AddToScheme(scheme)
 	_ = openfunctionevent.AddToScheme(scheme)
 	_ = shipwrightv1alpha1.AddToScheme(scheme)
+	_ = gatewayv1alpha1.AddToScheme(scheme)
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -68,12 +86,32 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var functionConcurrency, builderConcurrency, servingConcurrency, domainConcurrency int
+	var watchNamespaces string
+	var cacheLabelledObjectsOnly bool
+	var configNamespace, configName string
+	var configReloadInterval time.Duration
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.IntVar(&functionConcurrency, "function-max-concurrent-reconciles", 1, "The maximum number of Functions reconciled concurrently.")
+	flag.IntVar(&builderConcurrency, "builder-max-concurrent-reconciles", 1, "The maximum number of Builders reconciled concurrently.")
+	flag.IntVar(&servingConcurrency, "serving-max-concurrent-reconciles", 1, "The maximum number of Servings reconciled concurrently.")
+	flag.IntVar(&domainConcurrency, "domain-max-concurrent-reconciles", 1, "The maximum number of Domains reconciled concurrently.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "",
+		"Comma separated namespaces the controller manager watches, all namespaces are watched if it is empty. "+
+			"Use it with the namespaced RBAC in config/namespaced.")
+	flag.BoolVar(&cacheLabelledObjectsOnly, "cache-labelled-objects-only", false,
+		"Only cache the child objects labelled by OpenFunction to reduce the memory usage. "+
+			"The child objects created by the versions which did not label them are ignored.")
+	flag.StringVar(&configNamespace, "config-namespace", getEnv("POD_NAMESPACE", "openfunction"),
+		"The namespace of the ConfigMap which overrides the defaults, such as the builder and handler images.")
+	flag.StringVar(&configName, "config-name", "openfunction-config",
+		"The name of the ConfigMap which overrides the defaults, the built-in defaults are used if it does not exist.")
+	flag.DurationVar(&configReloadInterval, "config-reload-interval", time.Minute, "How often the ConfigMap is reloaded.")
 
 	// Use `--zap-log-level=debug` to enable debug log.
 	opts := zap.Options{
@@ -92,29 +130,49 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "79f0111e.openfunction.io",
+		NewCache:               newCache(splitNamespaces(watchNamespaces), cacheLabelledObjectsOnly),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	// Load the config before the reconcilers start, so that they never see the built-in defaults by mistake.
+	loader := &config.Loader{
+		Reader:    mgr.GetAPIReader(),
+		Namespace: configNamespace,
+		Name:      configName,
+		Interval:  configReloadInterval,
+		Log:       ctrl.Log.WithName("config"),
+	}
+	if err := loader.Load(context.Background()); err != nil {
+		setupLog.Error(err, "unable to load config")
+		os.Exit(1)
+	}
+	if err := mgr.Add(loader); err != nil {
+		setupLog.Error(err, "unable to set up config loader")
+		os.Exit(1)
+	}
+
 	if err = (&core.FunctionReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Function"),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("Function"),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  mgr.GetEventRecorderFor("function-controller"),
+		APIReader: mgr.GetAPIReader(),
+	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: functionConcurrency}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Function")
 		os.Exit(1)
 	}
-	if err = core.NewBuilderReconciler(mgr).SetupWithManager(mgr, builder.Registry()); err != nil {
+	if err = core.NewBuilderReconciler(mgr).SetupWithManager(mgr, ofcore.BuilderRunOwns(), controller.Options{MaxConcurrentReconciles: builderConcurrency}); err != nil {
 		setupLog.Error(err, "unable to create builder controller")
 		os.Exit(1)
 	}
-	if err = core.NewServingReconciler(mgr).SetupWithManager(mgr, serving.Registry()); err != nil {
+	if err = core.NewServingReconciler(mgr).SetupWithManager(mgr, ofcore.ServingRunOwns(), controller.Options{MaxConcurrentReconciles: servingConcurrency}); err != nil {
 		setupLog.Error(err, "unable to create serving controller")
 		os.Exit(1)
 	}
-	if err = core.NewDomainReconciler(mgr).SetupWithManager(mgr); err != nil {
+	if err = core.NewDomainReconciler(mgr).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: domainConcurrency}); err != nil {
 		setupLog.Error(err, "unable to create domain controller")
 		os.Exit(1)
 	}
@@ -143,9 +201,18 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "Function")
 			os.Exit(1)
 		}
+		if err = (&corev1alpha2.Domain{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Domain")
+			os.Exit(1)
+		}
 	}
 	//+kubebuilder:scaffold:builder
 
+	if err := metrics.RegisterFunctionCollector(mgr.GetClient()); err != nil {
+		setupLog.Error(err, "unable to register metrics collector")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -161,3 +228,69 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func splitNamespaces(s string) []string {
+	var namespaces []string
+	for _, ns := range strings.Split(s, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// Create the cache which watches the given namespaces only, all namespaces are watched if there is none.
+func newCache(namespaces []string, labelledObjectsOnly bool) cache.NewCacheFunc {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		if labelledObjectsOnly {
+			opts.SelectorsByObject = cacheSelectors()
+		}
+
+		switch len(namespaces) {
+		case 0:
+			return cache.New(config, opts)
+		case 1:
+			opts.Namespace = namespaces[0]
+			return cache.New(config, opts)
+		default:
+			return cache.MultiNamespacedCacheBuilder(namespaces)(config, opts)
+		}
+	}
+}
+
+// The child objects are selected by the labels set by OpenFunction.
+// Services and jobs are not filtered, as they are created by different controllers with different labels,
+// or they are not created by OpenFunction at all, such as the services of the ingress controllers.
+// Ingresses are not filtered either, so that the unlabelled `openfunction` ingress created by the earlier versions
+// is still visible and the paths of the functions are removed from it.
+func cacheSelectors() cache.SelectorsByObject {
+	exists := func(key string) labels.Selector {
+		req, err := labels.NewRequirement(key, selection.Exists, nil)
+		if err != nil {
+			panic(err)
+		}
+		return labels.NewSelector().Add(*req)
+	}
+
+	serving, builder := exists(constants.ServingLabel), exists(constants.BuilderLabel)
+	return cache.SelectorsByObject{
+		&appsv1.Deployment{}:                     {Label: serving},
+		&appsv1.StatefulSet{}:                    {Label: serving},
+		&autoscalingv1.HorizontalPodAutoscaler{}: {Label: serving},
+		&kservingv1.Service{}:                    {Label: serving},
+		&kedav1alpha1.ScaledObject{}:             {Label: serving},
+		&kedav1alpha1.ScaledJob{}:                {Label: serving},
+		&componentsv1alpha1.Component{}:          {Label: serving},
+		&shipwrightv1alpha1.Build{}:              {Label: builder},
+		&shipwrightv1alpha1.BuildRun{}:           {Label: builder},
+		&corev1.Pod{}:                            {Label: builder},
+		&gatewayv1alpha1.HTTPRoute{}:             {Label: exists(constants.FunctionLabel)},
+	}
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openfunction/pkg/util"
+)
+
+// The keys of the ConfigMap which overrides the defaults.
+const (
+	ShipwrightStrategyKey     = "builder.shipwright.strategy"
+	ShipwrightStrategyKindKey = "builder.shipwright.strategyKind"
+	KanikoImageKey            = "builder.kaniko.image"
+	KanikoGitImageKey         = "builder.kaniko.gitImage"
+	ServingPortKey            = "serving.port"
+	ClusterDomainKey          = "serving.clusterDomain"
+	DaprAppProtocolKey        = "serving.dapr.appProtocol"
+	EventSourceHandlerKey     = "events.eventSourceHandlerImage"
+	TriggerHandlerKey         = "events.triggerHandlerImage"
+	LogLevelKey               = "events.logLevel"
+)
+
+// Config holds the defaults used by the reconcilers when the resources do not set them.
+type Config struct {
+	// The build strategy used by the Shipwright builders.
+	ShipwrightStrategy string
+	// The kind of the build strategy, `ClusterBuildStrategy` or `BuildStrategy`.
+	ShipwrightStrategyKind string
+	// The image of the Kaniko executor.
+	KanikoImage string
+	// The image which clones the source of the Kaniko builds.
+	KanikoGitImage string
+	// The port the functions listen on.
+	ServingPort int32
+	// The domain of the cluster, the services are resolved as `<name>.<namespace>.svc.<domain>`.
+	ClusterDomain string
+	// The protocol Dapr uses to talk to the OpenFuncAsync functions.
+	DaprAppProtocol string
+	// The image of the EventSource handler.
+	EventSourceHandlerImage string
+	// The image of the Trigger handler.
+	TriggerHandlerImage string
+	// The log level of the EventSource and Trigger handlers.
+	LogLevel string
+}
+
+var (
+	lock    sync.RWMutex
+	current = Default()
+)
+
+// Default returns the built-in defaults.
+func Default() *Config {
+	return &Config{
+		ShipwrightStrategy:      "openfunction",
+		ShipwrightStrategyKind:  "ClusterBuildStrategy",
+		KanikoImage:             "gcr.io/kaniko-project/executor:v1.7.0",
+		KanikoGitImage:          "alpine/git:v2.32.0",
+		ServingPort:             8080,
+		ClusterDomain:           "cluster.local",
+		DaprAppProtocol:         "grpc",
+		EventSourceHandlerImage: "openfunctiondev/eventsource-handler:v2",
+		TriggerHandlerImage:     "openfunctiondev/trigger-handler:v2",
+		LogLevel:                "info",
+	}
+}
+
+// Get returns the config currently in use, it must not be modified.
+func Get() *Config {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return current
+}
+
+// Set replaces the config in use.
+func Set(c *Config) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	current = c
+}
+
+// Parse overrides the defaults with the data of the ConfigMap, the unknown keys are ignored.
+func Parse(data map[string]string) (*Config, error) {
+	c := Default()
+
+	strs := map[string]*string{
+		ShipwrightStrategyKey:     &c.ShipwrightStrategy,
+		ShipwrightStrategyKindKey: &c.ShipwrightStrategyKind,
+		KanikoImageKey:            &c.KanikoImage,
+		KanikoGitImageKey:         &c.KanikoGitImage,
+		ClusterDomainKey:          &c.ClusterDomain,
+		DaprAppProtocolKey:        &c.DaprAppProtocol,
+		EventSourceHandlerKey:     &c.EventSourceHandlerImage,
+		TriggerHandlerKey:         &c.TriggerHandlerImage,
+		LogLevelKey:               &c.LogLevel,
+	}
+	for k, p := range strs {
+		if v, ok := data[k]; ok && v != "" {
+			*p = v
+		}
+	}
+
+	if v, ok := data[ServingPortKey]; ok && v != "" {
+		port, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || port <= 0 {
+			return nil, fmt.Errorf("invalid %s: %s", ServingPortKey, v)
+		}
+		c.ServingPort = int32(port)
+	}
+
+	if c.ShipwrightStrategyKind != "ClusterBuildStrategy" && c.ShipwrightStrategyKind != "BuildStrategy" {
+		return nil, fmt.Errorf("invalid %s: %s", ShipwrightStrategyKindKey, c.ShipwrightStrategyKind)
+	}
+
+	return c, nil
+}
+
+// ServiceHost returns the fully qualified host of the service in the cluster.
+func (c *Config) ServiceHost(name, namespace string) string {
+	return fmt.Sprintf("%s.%s.svc.%s", name, namespace, c.ClusterDomain)
+}
+
+// Loader loads the config from a ConfigMap, the built-in defaults are used if the ConfigMap does not exist.
+type Loader struct {
+	Reader    client.Reader
+	Namespace string
+	Name      string
+	// How often the ConfigMap is reloaded.
+	Interval time.Duration
+	Log      logr.Logger
+}
+
+// Load reads the ConfigMap and replaces the config in use.
+func (l *Loader) Load(ctx context.Context) error {
+	cm := &corev1.ConfigMap{}
+	if err := l.Reader.Get(ctx, client.ObjectKey{Namespace: l.Namespace, Name: l.Name}, cm); err != nil {
+		if util.IsNotFound(err) {
+			Set(Default())
+			return nil
+		}
+		return err
+	}
+
+	c, err := Parse(cm.Data)
+	if err != nil {
+		return err
+	}
+
+	Set(c)
+	return nil
+}
+
+// Start reloads the ConfigMap periodically until the context is done.
+// An invalid ConfigMap is logged, and the config in use is kept.
+func (l *Loader) Start(ctx context.Context) error {
+	ticker := time.NewTicker(l.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := l.Load(ctx); err != nil {
+				l.Log.Error(err, "Failed to load config", "ConfigMap", fmt.Sprintf("%s/%s", l.Namespace, l.Name))
+			}
+		}
+	}
+}
+
+// NeedLeaderElection makes every replica reload the config, not only the leader.
+func (l *Loader) NeedLeaderElection() bool {
+	return false
+}
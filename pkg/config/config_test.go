@@ -0,0 +1,94 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    func(c *Config)
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			data: nil,
+			want: func(c *Config) {},
+		},
+		{
+			name: "unknown keys and empty values are ignored",
+			data: map[string]string{
+				"unknown":             "value",
+				ShipwrightStrategyKey: "",
+			},
+			want: func(c *Config) {},
+		},
+		{
+			name: "overrides",
+			data: map[string]string{
+				ShipwrightStrategyKey:     "buildpacks",
+				ShipwrightStrategyKindKey: "BuildStrategy",
+				KanikoImageKey:            "kaniko:latest",
+				KanikoGitImageKey:         "git:latest",
+				ServingPortKey:            "9090",
+				ClusterDomainKey:          "example.internal",
+				DaprAppProtocolKey:        "http",
+				EventSourceHandlerKey:     "eventsource:latest",
+				TriggerHandlerKey:         "trigger:latest",
+				LogLevelKey:               "debug",
+			},
+			want: func(c *Config) {
+				c.ShipwrightStrategy = "buildpacks"
+				c.ShipwrightStrategyKind = "BuildStrategy"
+				c.KanikoImage = "kaniko:latest"
+				c.KanikoGitImage = "git:latest"
+				c.ServingPort = 9090
+				c.ClusterDomain = "example.internal"
+				c.DaprAppProtocol = "http"
+				c.EventSourceHandlerImage = "eventsource:latest"
+				c.TriggerHandlerImage = "trigger:latest"
+				c.LogLevel = "debug"
+			},
+		},
+		{
+			name:    "port not a number",
+			data:    map[string]string{ServingPortKey: "http"},
+			wantErr: true,
+		},
+		{
+			name:    "port not positive",
+			data:    map[string]string{ServingPortKey: "0"},
+			wantErr: true,
+		},
+		{
+			name:    "port out of range",
+			data:    map[string]string{ServingPortKey: "4294967296"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown strategy kind",
+			data:    map[string]string{ShipwrightStrategyKindKey: "Strategy"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			want := Default()
+			tt.want(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Parse() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
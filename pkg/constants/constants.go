@@ -1,5 +1,9 @@
 package constants
 
 const (
-	FunctionLabel = "openfunction.io/function"
+	FunctionLabel        = "openfunction.io/function"
+	DomainLabel          = "openfunction.io/domain"
+	DomainNamespaceLabel = "openfunction.io/domain-namespace"
+	ServingLabel         = "openfunction.io/serving"
+	BuilderLabel         = "openfunction.io/builder"
 )
@@ -0,0 +1,410 @@
+package kaniko
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+	"github.com/openfunction/pkg/config"
+	"github.com/openfunction/pkg/constants"
+	"github.com/openfunction/pkg/core"
+	"github.com/openfunction/pkg/util"
+)
+
+const (
+	kanikoJobName = "kaniko/job"
+	jobNameLabel  = "job-name"
+
+	// The reason of the Failed condition when the job runs longer than its deadline.
+	jobDeadlineExceeded = "DeadlineExceeded"
+
+	defaultDockerfile = "Dockerfile"
+
+	sourceContainer = "source"
+	buildContainer  = "build"
+
+	workspaceVolume  = "workspace"
+	workspacePath    = "/workspace"
+	sourcePath       = "/workspace/source"
+	dockerConfig     = "docker-config"
+	dockerConfigPath = "/kaniko/.docker"
+)
+
+type builderRun struct {
+	client.Client
+	ctx    context.Context
+	log    logr.Logger
+	scheme *runtime.Scheme
+}
+
+func NewBuildRun(ctx context.Context, c client.Client, scheme *runtime.Scheme, log logr.Logger) core.BuilderRun {
+
+	return &builderRun{
+		c,
+		ctx,
+		log.WithName("Kaniko"),
+		scheme,
+	}
+}
+
+func Registry() []client.Object {
+
+	return []client.Object{&batchv1.Job{}}
+}
+
+func (r *builderRun) Start(builder *openfunction.Builder) error {
+
+	log := r.log.WithName("Start").
+		WithValues("Builder", fmt.Sprintf("%s/%s", builder.Namespace, builder.Name))
+
+	// Clean up redundant jobs caused by the `Start` function failed.
+	if err := r.Clean(builder); err != nil {
+		log.Error(err, "Clean failed")
+		return err
+	}
+
+	job := r.createJob(builder)
+	if err := ctrl.SetControllerReference(builder, job, r.scheme); err != nil {
+		log.Error(err, "Failed to SetControllerReference for Job", "Job", job.Name)
+		return err
+	}
+
+	if err := r.Create(r.ctx, job); err != nil {
+		log.Error(err, "Failed to create Job", "Job", job.Name)
+		return err
+	}
+
+	log.V(1).Info("Job created", "Job", job.Name)
+
+	builder.Status.ResourceRef = map[string]string{
+		kanikoJobName: job.Name,
+	}
+
+	return nil
+}
+
+func (r *builderRun) Result(builder *openfunction.Builder) (string, error) {
+	log := r.log.WithName("Result").
+		WithValues("Builder", fmt.Sprintf("%s/%s", builder.Namespace, builder.Name))
+
+	// The result is unknown until the job is created.
+	name := getName(builder, kanikoJobName)
+	if name == "" {
+		return "", nil
+	}
+
+	job := &batchv1.Job{}
+	if err := r.Get(r.ctx, client.ObjectKey{Name: name, Namespace: builder.Namespace}, job); util.IgnoreNotFound(err) != nil {
+		log.Error(err, "Failed to get Job", "Job", name)
+		return "", util.IgnoreNotFound(err)
+	}
+
+	var res string
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch cond.Type {
+		case batchv1.JobComplete:
+			res = openfunction.Succeeded
+		case batchv1.JobFailed:
+			res = openfunction.Failed
+			// The job fails with this reason when it runs longer than `activeDeadlineSeconds`.
+			if cond.Reason == jobDeadlineExceeded {
+				res = openfunction.Timeout
+			}
+		default:
+			continue
+		}
+
+		builder.Status.Reason = cond.Reason
+		builder.Status.Message = cond.Message
+		completionTime := cond.LastTransitionTime
+		builder.Status.CompletionTime = &completionTime
+	}
+
+	if res == "" {
+		return "", nil
+	}
+
+	if err := r.inspectPod(builder, job); err != nil {
+		log.Error(err, "Failed to inspect the pod of Job", "Job", job.Name)
+		return "", err
+	}
+
+	return res, nil
+}
+
+// Record the pod which ran the build, the step which failed, and the digest of the image
+// which is written to the termination message by the Kaniko executor.
+func (r *builderRun) inspectPod(builder *openfunction.Builder, job *batchv1.Job) error {
+	pods := &corev1.PodList{}
+	if err := r.List(r.ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{jobNameLabel: job.Name}); err != nil {
+		return err
+	}
+
+	if len(pods.Items) == 0 {
+		return nil
+	}
+
+	// The job does not retry, so there is only one pod unless it was evicted.
+	pod := pods.Items[0]
+	for _, item := range pods.Items[1:] {
+		if item.CreationTimestamp.After(pod.CreationTimestamp.Time) {
+			pod = item
+		}
+	}
+
+	builder.Status.Pod = pod.Name
+
+	statuses := append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		terminated := status.State.Terminated
+		if terminated == nil {
+			continue
+		}
+
+		if terminated.ExitCode != 0 {
+			builder.Status.FailedStep = status.Name
+			break
+		}
+
+		if status.Name == buildContainer {
+			builder.Status.Digest = strings.TrimSpace(terminated.Message)
+		}
+	}
+
+	return nil
+}
+
+// Clean up redundant jobs caused by the `Start` function failed.
+func (r *builderRun) Clean(builder *openfunction.Builder) error {
+	log := r.log.WithName("Clean").
+		WithValues("Builder", fmt.Sprintf("%s/%s", builder.Namespace, builder.Name))
+
+	jobs := &batchv1.JobList{}
+	if err := r.List(r.ctx, jobs, client.InNamespace(builder.Namespace), client.MatchingLabels{constants.BuilderLabel: builder.Name}); err != nil {
+		return err
+	}
+
+	for _, item := range jobs.Items {
+		if strings.HasPrefix(item.Name, builder.Name) {
+			if err := r.Delete(context.Background(), &item, client.PropagationPolicy(metav1.DeletePropagationBackground)); util.IgnoreNotFound(err) != nil {
+				return err
+			}
+			log.V(1).Info("Delete Job", "Job", item.Name)
+		}
+	}
+
+	return nil
+}
+
+func (r *builderRun) createJob(builder *openfunction.Builder) *batchv1.Job {
+
+	image := config.Get().KanikoImage
+	gitImage := config.Get().KanikoGitImage
+	var extraArgs []string
+	if kaniko := builder.Spec.Kaniko; kaniko != nil {
+		if kaniko.Image != nil {
+			image = *kaniko.Image
+		}
+		if kaniko.GitImage != nil {
+			gitImage = *kaniko.GitImage
+		}
+		extraArgs = kaniko.Args
+	}
+
+	volumes := []corev1.Volume{
+		{
+			Name: workspaceVolume,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+	workspaceMount := corev1.VolumeMount{
+		Name:      workspaceVolume,
+		MountPath: workspacePath,
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-kaniko-", builder.Name),
+			Namespace:    builder.Namespace,
+			Labels: map[string]string{
+				constants.BuilderLabel: builder.Name,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			// The build is not retried, a failed build is reported to the function at once.
+			BackoffLimit: new(int32),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						constants.BuilderLabel: builder.Name,
+					},
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					InitContainers: []corev1.Container{
+						r.createSourceContainer(builder, gitImage, workspaceMount),
+					},
+					Containers: []corev1.Container{
+						{
+							Name:                     buildContainer,
+							Image:                    image,
+							Args:                     append(buildArgs(builder), extraArgs...),
+							VolumeMounts:             []corev1.VolumeMount{workspaceMount},
+							TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+						},
+					},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+
+	if builder.Spec.Timeout != nil {
+		start := builder.CreationTimestamp.Time
+		if builder.Status.StartTime != nil {
+			start = builder.Status.StartTime.Time
+		}
+		remaining := int64((builder.Spec.Timeout.Duration - time.Since(start)).Seconds())
+		if remaining < 1 {
+			remaining = 1
+		}
+		job.Spec.ActiveDeadlineSeconds = &remaining
+	}
+
+	// The credentials to push the image, the secret must be of type `kubernetes.io/dockerconfigjson`.
+	if builder.Spec.ImageCredentials != nil && builder.Spec.ImageCredentials.Name != "" {
+		podSpec := &job.Spec.Template.Spec
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: dockerConfig,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: builder.Spec.ImageCredentials.Name,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  corev1.DockerConfigJsonKey,
+							Path: "config.json",
+						},
+					},
+				},
+			},
+		})
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      dockerConfig,
+			MountPath: dockerConfigPath,
+		})
+	}
+
+	job.SetOwnerReferences(nil)
+	return job
+}
+
+// The source code is cloned into the workspace, the credentials are read from a secret of type `kubernetes.io/basic-auth`.
+func (r *builderRun) createSourceContainer(builder *openfunction.Builder, image string, mount corev1.VolumeMount) corev1.Container {
+
+	repo := builder.Spec.SrcRepo
+	script := `set -e
+git -c credential.helper='!f() { echo "username=${GIT_USERNAME}"; echo "password=${GIT_PASSWORD}"; }; f' clone "${GIT_URL}" ` + sourcePath + `
+cd ` + sourcePath + `
+if [ -n "${GIT_REVISION}" ]; then
+  git -c credential.helper='!f() { echo "username=${GIT_USERNAME}"; echo "password=${GIT_PASSWORD}"; }; f' fetch origin "${GIT_REVISION}"
+  git checkout FETCH_HEAD
+fi
+`
+
+	env := []corev1.EnvVar{
+		{
+			Name:  "GIT_URL",
+			Value: repo.Url,
+		},
+	}
+	if repo.Revision != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  "GIT_REVISION",
+			Value: *repo.Revision,
+		})
+	}
+
+	if repo.Credentials != nil && repo.Credentials.Name != "" {
+		optional := true
+		for name, key := range map[string]string{
+			"GIT_USERNAME": corev1.BasicAuthUsernameKey,
+			"GIT_PASSWORD": corev1.BasicAuthPasswordKey,
+		} {
+			env = append(env, corev1.EnvVar{
+				Name: name,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: *repo.Credentials,
+						Key:                  key,
+						Optional:             &optional,
+					},
+				},
+			})
+		}
+	}
+
+	return corev1.Container{
+		Name:         sourceContainer,
+		Image:        image,
+		Command:      []string{"/bin/sh", "-c"},
+		Args:         []string{script},
+		Env:          env,
+		VolumeMounts: []corev1.VolumeMount{mount},
+	}
+}
+
+// The arguments of the Kaniko executor, the environment variables of the builder are passed as build arguments,
+// and the digest of the image is written to the termination message.
+func buildArgs(builder *openfunction.Builder) []string {
+
+	contextDir := sourcePath
+	if builder.Spec.SrcRepo.SourceSubPath != nil && *builder.Spec.SrcRepo.SourceSubPath != "" {
+		contextDir = path.Join(sourcePath, *builder.Spec.SrcRepo.SourceSubPath)
+	}
+
+	dockerfile := defaultDockerfile
+	if builder.Spec.Dockerfile != nil && *builder.Spec.Dockerfile != "" {
+		dockerfile = *builder.Spec.Dockerfile
+	}
+
+	args := []string{
+		fmt.Sprintf("--context=dir://%s", contextDir),
+		fmt.Sprintf("--dockerfile=%s", path.Join(contextDir, dockerfile)),
+		fmt.Sprintf("--destination=%s", builder.Spec.Image),
+		"--digest-file=/dev/termination-log",
+	}
+
+	for k, v := range builder.Spec.Env {
+		args = append(args, fmt.Sprintf("--build-arg=%s=%s", k, v))
+	}
+	if builder.Spec.Port != nil {
+		args = append(args, fmt.Sprintf("--build-arg=PORT=%d", *builder.Spec.Port))
+	}
+
+	return args
+}
+
+func getName(builder *openfunction.Builder, key string) string {
+	if builder.Status.ResourceRef == nil {
+		return ""
+	}
+
+	return builder.Status.ResourceRef[key]
+}
@@ -0,0 +1,132 @@
+package kaniko
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+)
+
+func TestResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		resource   map[string]string
+		conditions []batchv1.JobCondition
+		want       string
+	}{
+		{
+			name: "job not created",
+			want: "",
+		},
+		{
+			name:     "job not found",
+			resource: map[string]string{kanikoJobName: "missing"},
+			want:     "",
+		},
+		{
+			name:     "job running",
+			resource: map[string]string{kanikoJobName: "build-job"},
+			want:     "",
+		},
+		{
+			name:     "job complete",
+			resource: map[string]string{kanikoJobName: "build-job"},
+			conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+			},
+			want: openfunction.Succeeded,
+		},
+		{
+			name:     "job failed",
+			resource: map[string]string{kanikoJobName: "build-job"},
+			conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"},
+			},
+			want: openfunction.Failed,
+		},
+		{
+			name:     "job deadline exceeded",
+			resource: map[string]string{kanikoJobName: "build-job"},
+			conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: jobDeadlineExceeded},
+			},
+			want: openfunction.Timeout,
+		},
+		{
+			name:     "condition not true",
+			resource: map[string]string{kanikoJobName: "build-job"},
+			conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionFalse},
+			},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: "build-job", Namespace: "default"},
+				Status:     batchv1.JobStatus{Conditions: tt.conditions},
+			}
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(job).Build()
+			r := NewBuildRun(context.Background(), c, clientgoscheme.Scheme, logr.Discard())
+
+			builder := &openfunction.Builder{
+				ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "default"},
+				Status:     openfunction.BuilderStatus{ResourceRef: tt.resource},
+			}
+			got, err := r.Result(builder)
+			if err != nil {
+				t.Fatalf("Result() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Result() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultDigest(t *testing.T) {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-job", Namespace: "default"},
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-job-abcde", Namespace: "default", Labels: map[string]string{jobNameLabel: "build-job"}},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  buildContainer,
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: "sha256:1234\n"}},
+				},
+			},
+		},
+	}
+	objs := []client.Object{job, pod}
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(objs...).Build()
+	r := NewBuildRun(context.Background(), c, clientgoscheme.Scheme, logr.Discard())
+
+	builder := &openfunction.Builder{
+		ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "default"},
+		Status:     openfunction.BuilderStatus{ResourceRef: map[string]string{kanikoJobName: "build-job"}},
+	}
+	if _, err := r.Result(builder); err != nil {
+		t.Fatalf("Result() error = %v", err)
+	}
+	if builder.Status.Pod != pod.Name {
+		t.Errorf("Pod = %q, want %q", builder.Status.Pod, pod.Name)
+	}
+	if builder.Status.Digest != "sha256:1234" {
+		t.Errorf("Digest = %q, want %q", builder.Status.Digest, "sha256:1234")
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	openfunction "github.com/openfunction/apis/core/v1alpha2"
+	"github.com/openfunction/pkg/config"
+	"github.com/openfunction/pkg/constants"
 	"github.com/openfunction/pkg/core"
 	"github.com/openfunction/pkg/util"
 )
@@ -22,8 +24,6 @@ import (
 const (
 	shipwrightBuildName    = "shipwright.io/build"
 	shipwrightBuildRunName = "shipwright.io/buildRun"
-	builderLabel           = "openfunction.io/builder"
-	defaultStrategy        = "openfunction"
 
 	envVars  = "ENV_VARS"
 	appImage = "APP_IMAGE"
@@ -111,8 +111,16 @@ func (r *builderRun) Result(builder *openfunction.Builder) (string, error) {
 		return "", util.IgnoreNotFound(err)
 	}
 
-	if shipwrightBuild.Status.Registered != corev1.ConditionTrue {
-		return string(shipwrightBuild.Status.Reason), nil
+	switch shipwrightBuild.Status.Registered {
+	case corev1.ConditionTrue:
+	case corev1.ConditionFalse:
+		// The build is invalid, e.g. the build strategy or the secret does not exist.
+		builder.Status.Reason = string(shipwrightBuild.Status.Reason)
+		builder.Status.Message = shipwrightBuild.Status.Message
+		return openfunction.Failed, nil
+	default:
+		// The build has not been validated.
+		return "", nil
 	}
 
 	shipwrightBuildRun := &shipwrightv1alpha1.BuildRun{
@@ -126,10 +134,28 @@ func (r *builderRun) Result(builder *openfunction.Builder) (string, error) {
 		return "", util.IgnoreNotFound(err)
 	}
 
+	if shipwrightBuildRun.Status.LatestTaskRunRef != nil {
+		builder.Status.TaskRun = *shipwrightBuildRun.Status.LatestTaskRunRef
+	}
+	builder.Status.CompletionTime = shipwrightBuildRun.Status.CompletionTime
 	if shipwrightBuildRun.Status.CompletionTime == nil {
 		return "", nil
 	}
 
+	if c := shipwrightBuildRun.Status.GetCondition(shipwrightv1alpha1.Succeeded); c != nil {
+		builder.Status.Reason = c.GetReason()
+		builder.Status.Message = c.GetMessage()
+	}
+
+	if shipwrightBuildRun.Status.FailedAt != nil {
+		builder.Status.Pod = shipwrightBuildRun.Status.FailedAt.Pod
+		builder.Status.FailedStep = shipwrightBuildRun.Status.FailedAt.Container
+	}
+
+	if shipwrightBuildRun.Status.Output != nil {
+		builder.Status.Digest = shipwrightBuildRun.Status.Output.Digest
+	}
+
 	if shipwrightBuildRun.Status.IsFailed(shipwrightv1alpha1.Succeeded) {
 		return openfunction.Failed, nil
 	} else {
@@ -143,7 +169,7 @@ func (r *builderRun) Clean(builder *openfunction.Builder) error {
 		WithValues("Builder", fmt.Sprintf("%s/%s", builder.Namespace, builder.Name))
 
 	builds := &shipwrightv1alpha1.BuildList{}
-	if err := r.List(r.ctx, builds, client.InNamespace(builder.Namespace), client.MatchingLabels{builderLabel: builder.Name}); err != nil {
+	if err := r.List(r.ctx, builds, client.InNamespace(builder.Namespace), client.MatchingLabels{constants.BuilderLabel: builder.Name}); err != nil {
 		return err
 	}
 
@@ -157,7 +183,7 @@ func (r *builderRun) Clean(builder *openfunction.Builder) error {
 	}
 
 	buildRuns := &shipwrightv1alpha1.BuildRunList{}
-	if err := r.List(r.ctx, buildRuns, client.InNamespace(builder.Namespace), client.MatchingLabels{builderLabel: builder.Name}); err != nil {
+	if err := r.List(r.ctx, buildRuns, client.InNamespace(builder.Namespace), client.MatchingLabels{constants.BuilderLabel: builder.Name}); err != nil {
 		return err
 	}
 
@@ -180,7 +206,7 @@ func (r *builderRun) createShipwrightBuild(builder *openfunction.Builder) *shipw
 			GenerateName: fmt.Sprintf("%s-build-", builder.Name),
 			Namespace:    builder.Namespace,
 			Labels: map[string]string{
-				builderLabel: builder.Name,
+				constants.BuilderLabel: builder.Name,
 			},
 		},
 		Spec: shipwrightv1alpha1.BuildSpec{
@@ -203,8 +229,12 @@ func (r *builderRun) createShipwrightBuild(builder *openfunction.Builder) *shipw
 	}
 
 	if builder.Spec.Timeout != nil {
+		start := builder.CreationTimestamp.Time
+		if builder.Status.StartTime != nil {
+			start = builder.Status.StartTime.Time
+		}
 		shipwrightBuild.Spec.Timeout = &metav1.Duration{
-			Duration: builder.Spec.Timeout.Duration - time.Since(builder.CreationTimestamp.Time),
+			Duration: builder.Spec.Timeout.Duration - time.Since(start),
 		}
 	}
 
@@ -234,9 +264,9 @@ func (r *builderRun) createShipwrightBuild(builder *openfunction.Builder) *shipw
 	})
 
 	if builder.Spec.Shipwright == nil || builder.Spec.Shipwright.Strategy == nil {
-		kind := shipwrightv1alpha1.ClusterBuildStrategyKind
+		kind := shipwrightv1alpha1.BuildStrategyKind(config.Get().ShipwrightStrategyKind)
 		shipwrightBuild.Spec.Strategy = &shipwrightv1alpha1.Strategy{
-			Name: defaultStrategy,
+			Name: config.Get().ShipwrightStrategy,
 			Kind: &kind,
 		}
 	}
@@ -265,7 +295,7 @@ func (r *builderRun) createShipwrightBuildRun(builder *openfunction.Builder, nam
 			GenerateName: fmt.Sprintf("%s-buildrun-", builder.Name),
 			Namespace:    builder.Namespace,
 			Labels: map[string]string{
-				builderLabel: builder.Name,
+				constants.BuilderLabel: builder.Name,
 			},
 		},
 		Spec: shipwrightv1alpha1.BuildRunSpec{
@@ -279,6 +309,15 @@ func (r *builderRun) createShipwrightBuildRun(builder *openfunction.Builder, nam
 	return shipwrightBuildRun
 }
 
+// GetStrategyName returns the name of the build strategy used by the builder.
+func GetStrategyName(builder *openfunction.Builder) string {
+	if builder.Spec.Shipwright == nil || builder.Spec.Shipwright.Strategy == nil {
+		return config.Get().ShipwrightStrategy
+	}
+
+	return builder.Spec.Shipwright.Strategy.Name
+}
+
 func getName(builder *openfunction.Builder, key string) string {
 	if builder.Status.ResourceRef == nil {
 		return ""
@@ -0,0 +1,154 @@
+package shipwright
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	shipwrightv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+)
+
+func TestResult(t *testing.T) {
+	completed := metav1.Now()
+	taskRun := "build-run-taskrun"
+
+	tests := []struct {
+		name       string
+		registered corev1.ConditionStatus
+		buildRun   *shipwrightv1alpha1.BuildRunStatus
+		want       string
+		wantStatus openfunction.BuilderStatus
+	}{
+		{
+			name:       "build not validated",
+			registered: "",
+			want:       "",
+		},
+		{
+			name:       "build invalid",
+			registered: corev1.ConditionFalse,
+			want:       openfunction.Failed,
+			wantStatus: openfunction.BuilderStatus{
+				Reason:  string(shipwrightv1alpha1.BuildStrategyNotFound),
+				Message: "strategy not found",
+			},
+		},
+		{
+			name:       "buildrun not found",
+			registered: corev1.ConditionTrue,
+			want:       "",
+		},
+		{
+			name:       "buildrun running",
+			registered: corev1.ConditionTrue,
+			buildRun:   &shipwrightv1alpha1.BuildRunStatus{LatestTaskRunRef: &taskRun},
+			want:       "",
+			wantStatus: openfunction.BuilderStatus{TaskRun: taskRun},
+		},
+		{
+			name:       "buildrun succeeded",
+			registered: corev1.ConditionTrue,
+			buildRun: &shipwrightv1alpha1.BuildRunStatus{
+				LatestTaskRunRef: &taskRun,
+				CompletionTime:   &completed,
+				Conditions: shipwrightv1alpha1.Conditions{
+					{Type: shipwrightv1alpha1.Succeeded, Status: corev1.ConditionTrue, Reason: "Succeeded", Message: "done"},
+				},
+				Output: &shipwrightv1alpha1.Output{Digest: "sha256:1234"},
+			},
+			want: openfunction.Succeeded,
+			wantStatus: openfunction.BuilderStatus{
+				TaskRun:        taskRun,
+				CompletionTime: &completed,
+				Reason:         "Succeeded",
+				Message:        "done",
+				Digest:         "sha256:1234",
+			},
+		},
+		{
+			name:       "buildrun failed",
+			registered: corev1.ConditionTrue,
+			buildRun: &shipwrightv1alpha1.BuildRunStatus{
+				LatestTaskRunRef: &taskRun,
+				CompletionTime:   &completed,
+				Conditions: shipwrightv1alpha1.Conditions{
+					{Type: shipwrightv1alpha1.Succeeded, Status: corev1.ConditionFalse, Reason: "Failed", Message: "step failed"},
+				},
+				FailedAt: &shipwrightv1alpha1.FailedAt{Pod: "build-pod", Container: "step-build"},
+			},
+			want: openfunction.Failed,
+			wantStatus: openfunction.BuilderStatus{
+				TaskRun:        taskRun,
+				CompletionTime: &completed,
+				Reason:         "Failed",
+				Message:        "step failed",
+				Pod:            "build-pod",
+				FailedStep:     "step-build",
+			},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := shipwrightv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := []client.Object{
+				&shipwrightv1alpha1.Build{
+					ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "default"},
+					Status: shipwrightv1alpha1.BuildStatus{
+						Registered: tt.registered,
+						Reason:     shipwrightv1alpha1.BuildStrategyNotFound,
+						Message:    "strategy not found",
+					},
+				},
+			}
+			if tt.buildRun != nil {
+				objs = append(objs, &shipwrightv1alpha1.BuildRun{
+					ObjectMeta: metav1.ObjectMeta{Name: "build-run", Namespace: "default"},
+					Status:     *tt.buildRun,
+				})
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			r := NewBuildRun(context.Background(), c, scheme, logr.Discard())
+
+			builder := &openfunction.Builder{
+				ObjectMeta: metav1.ObjectMeta{Name: "builder", Namespace: "default"},
+				Status: openfunction.BuilderStatus{
+					ResourceRef: map[string]string{
+						shipwrightBuildName:    "build",
+						shipwrightBuildRunName: "build-run",
+					},
+				},
+			}
+			got, err := r.Result(builder)
+			if err != nil {
+				t.Fatalf("Result() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Result() = %q, want %q", got, tt.want)
+			}
+
+			// The completion time loses its precision through the client, only its presence is compared.
+			status := builder.Status
+			if status.TaskRun != tt.wantStatus.TaskRun ||
+				status.Reason != tt.wantStatus.Reason ||
+				status.Message != tt.wantStatus.Message ||
+				status.Pod != tt.wantStatus.Pod ||
+				status.FailedStep != tt.wantStatus.FailedStep ||
+				status.Digest != tt.wantStatus.Digest ||
+				(status.CompletionTime == nil) != (tt.wantStatus.CompletionTime == nil) {
+				t.Errorf("Status = %+v, want %+v", status, tt.wantStatus)
+			}
+		})
+	}
+}
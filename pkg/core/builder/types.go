@@ -1,11 +1,14 @@
 package builder
 
 import (
-	"sigs.k8s.io/controller-runtime/pkg/client"
-
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+	"github.com/openfunction/pkg/core"
+	"github.com/openfunction/pkg/core/builder/kaniko"
 	"github.com/openfunction/pkg/core/builder/shipwright"
 )
 
-func Registry() []client.Object {
-	return shipwright.Registry()
+// Register the built-in build engines, import this package to make them available.
+func init() {
+	core.RegisterBuilderRun(string(openfunction.Shipwright), shipwright.NewBuildRun, shipwright.Registry()...)
+	core.RegisterBuilderRun(string(openfunction.Kaniko), kaniko.NewBuildRun, kaniko.Registry()...)
 }
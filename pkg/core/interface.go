@@ -32,3 +32,11 @@ type ServingRun interface {
 	// Clean all resources which created by serving.
 	Clean(s *openfunction.Serving) error
 }
+
+// TrafficSplitter is implemented by the serving runtimes which can split the traffic
+// between a serving and a stable serving themselves.
+type TrafficSplitter interface {
+	// SplitTraffic applies `spec.traffic` of the serving.
+	// It returns true once the traffic split is in effect.
+	SplitTraffic(s *openfunction.Serving) (bool, error)
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ServingRunFactory creates the ServingRun of a runtime.
+type ServingRunFactory func(ctx context.Context, c client.Client, scheme *runtime.Scheme, log logr.Logger) ServingRun
+
+// BuilderRunFactory creates the BuilderRun of a build engine.
+type BuilderRunFactory func(ctx context.Context, c client.Client, scheme *runtime.Scheme, log logr.Logger) BuilderRun
+
+type servingRunEntry struct {
+	factory ServingRunFactory
+	owns    []client.Object
+}
+
+type builderRunEntry struct {
+	factory BuilderRunFactory
+	owns    []client.Object
+}
+
+var (
+	lock        sync.RWMutex
+	servingRuns = make(map[string]servingRunEntry)
+	builderRuns = make(map[string]builderRunEntry)
+)
+
+// RegisterServingRun registers the ServingRun of a runtime by its name, together with the types the runtime creates.
+// The Serving controller watches these types to reconcile the Serving which owns them.
+// It panics if the runtime had been registered.
+func RegisterServingRun(name string, factory ServingRunFactory, owns ...client.Object) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if _, ok := servingRuns[name]; ok {
+		panic(fmt.Sprintf("serving run %s registered twice", name))
+	}
+
+	servingRuns[name] = servingRunEntry{factory: factory, owns: owns}
+}
+
+// RegisterBuilderRun registers the BuilderRun of a build engine by its name, together with the types the engine creates.
+// The Builder controller watches these types to reconcile the Builder which owns them.
+// It panics if the engine had been registered.
+func RegisterBuilderRun(name string, factory BuilderRunFactory, owns ...client.Object) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if _, ok := builderRuns[name]; ok {
+		panic(fmt.Sprintf("builder run %s registered twice", name))
+	}
+
+	builderRuns[name] = builderRunEntry{factory: factory, owns: owns}
+}
+
+// NewServingRun creates the ServingRun of the runtime, it returns nil if the runtime is unknown.
+func NewServingRun(name string, ctx context.Context, c client.Client, scheme *runtime.Scheme, log logr.Logger) ServingRun {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	entry, ok := servingRuns[name]
+	if !ok {
+		return nil
+	}
+
+	return entry.factory(ctx, c, scheme, log)
+}
+
+// NewBuilderRun creates the BuilderRun of the build engine, it returns nil if the engine is unknown.
+func NewBuilderRun(name string, ctx context.Context, c client.Client, scheme *runtime.Scheme, log logr.Logger) BuilderRun {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	entry, ok := builderRuns[name]
+	if !ok {
+		return nil
+	}
+
+	return entry.factory(ctx, c, scheme, log)
+}
+
+// ServingRunOwns returns the types created by all the registered runtimes.
+func ServingRunOwns() []client.Object {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	var owns [][]client.Object
+	for _, name := range sortedKeys(servingRuns) {
+		owns = append(owns, servingRuns[name].owns)
+	}
+
+	return dedup(owns...)
+}
+
+// BuilderRunOwns returns the types created by all the registered build engines.
+func BuilderRunOwns() []client.Object {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	var owns [][]client.Object
+	for _, name := range sortedKeys(builderRuns) {
+		owns = append(owns, builderRuns[name].owns)
+	}
+
+	return dedup(owns...)
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Several backends may own the same type, watch it only once.
+func dedup(lists ...[]client.Object) []client.Object {
+	var res []client.Object
+	seen := make(map[reflect.Type]bool)
+	for _, list := range lists {
+		for _, obj := range list {
+			t := reflect.TypeOf(obj)
+			if seen[t] {
+				continue
+			}
+			seen[t] = true
+			res = append(res, obj)
+		}
+	}
+	return res
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type fakeServingRun struct {
+	ServingRun
+}
+
+type fakeBuilderRun struct {
+	BuilderRun
+}
+
+func newFakeServingRun(ctx context.Context, c client.Client, scheme *runtime.Scheme, log logr.Logger) ServingRun {
+	return &fakeServingRun{}
+}
+
+func newFakeBuilderRun(ctx context.Context, c client.Client, scheme *runtime.Scheme, log logr.Logger) BuilderRun {
+	return &fakeBuilderRun{}
+}
+
+func typesOf(objs []client.Object) []reflect.Type {
+	var types []reflect.Type
+	for _, obj := range objs {
+		types = append(types, reflect.TypeOf(obj))
+	}
+	return types
+}
+
+func assertPanics(t *testing.T, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("registered twice without panic")
+		}
+	}()
+	f()
+}
+
+func TestServingRunRegistry(t *testing.T) {
+	RegisterServingRun("TestServingA", newFakeServingRun, &appsv1.Deployment{}, &corev1.Service{})
+	RegisterServingRun("TestServingB", newFakeServingRun, &corev1.Service{}, &corev1.ConfigMap{})
+
+	assertPanics(t, func() { RegisterServingRun("TestServingA", newFakeServingRun) })
+
+	if run := NewServingRun("TestServingA", context.Background(), nil, nil, logr.Discard()); run == nil {
+		t.Errorf("NewServingRun() of a registered runtime = nil")
+	}
+	if run := NewServingRun("Unknown", context.Background(), nil, nil, logr.Discard()); run != nil {
+		t.Errorf("NewServingRun() of an unknown runtime = %v, want nil", run)
+	}
+
+	want := typesOf([]client.Object{&appsv1.Deployment{}, &corev1.Service{}, &corev1.ConfigMap{}})
+	if got := typesOf(ServingRunOwns()); !reflect.DeepEqual(got, want) {
+		t.Errorf("ServingRunOwns() = %v, want %v", got, want)
+	}
+}
+
+func TestBuilderRunRegistry(t *testing.T) {
+	RegisterBuilderRun("TestBuilderA", newFakeBuilderRun, &corev1.Pod{})
+	RegisterBuilderRun("TestBuilderB", newFakeBuilderRun, &corev1.Pod{}, &corev1.Secret{})
+
+	assertPanics(t, func() { RegisterBuilderRun("TestBuilderB", newFakeBuilderRun) })
+
+	if run := NewBuilderRun("TestBuilderA", context.Background(), nil, nil, logr.Discard()); run == nil {
+		t.Errorf("NewBuilderRun() of a registered engine = nil")
+	}
+	if run := NewBuilderRun("Unknown", context.Background(), nil, nil, logr.Discard()); run != nil {
+		t.Errorf("NewBuilderRun() of an unknown engine = %v, want nil", run)
+	}
+
+	want := typesOf([]client.Object{&corev1.Pod{}, &corev1.Secret{}})
+	if got := typesOf(BuilderRunOwns()); !reflect.DeepEqual(got, want) {
+		t.Errorf("BuilderRunOwns() = %v, want %v", got, want)
+	}
+}
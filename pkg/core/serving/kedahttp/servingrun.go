@@ -0,0 +1,283 @@
+package kedahttp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+	"github.com/openfunction/pkg/config"
+	"github.com/openfunction/pkg/constants"
+	"github.com/openfunction/pkg/core"
+	"github.com/openfunction/pkg/core/serving/workload"
+	"github.com/openfunction/pkg/util"
+)
+
+const (
+	deploymentName       = "KedaHTTP/deployment"
+	serviceName          = "KedaHTTP/service"
+	interceptorName      = "KedaHTTP/interceptor"
+	httpScaledObjectName = "KedaHTTP/httpscaledobject"
+
+	defaultTargetPendingRequests = 100
+	defaultMinReplicas           = 0
+	defaultMaxReplicas           = 100
+
+	defaultInterceptorName      = "keda-add-ons-http-interceptor-proxy"
+	defaultInterceptorNamespace = "keda"
+	defaultInterceptorPort      = 8080
+
+	// The type of the condition of the HTTPScaledObject which is true once KEDA scales the deployment.
+	httpScaledObjectReady = "Ready"
+)
+
+var httpScaledObjectGVK = schema.GroupVersionKind{
+	Group:   "http.keda.sh",
+	Version: "v1alpha1",
+	Kind:    "HTTPScaledObject",
+}
+
+type servingRun struct {
+	client.Client
+	ctx    context.Context
+	log    logr.Logger
+	scheme *runtime.Scheme
+}
+
+// The HTTPScaledObject is not watched, so that the controller can start without the KEDA HTTP add-on installed.
+func Registry() []client.Object {
+	return []client.Object{&appsv1.Deployment{}, &corev1.Service{}}
+}
+
+func NewServingRun(ctx context.Context, c client.Client, scheme *runtime.Scheme, log logr.Logger) core.ServingRun {
+	return &servingRun{
+		c,
+		ctx,
+		log.WithName("KedaHTTP"),
+		scheme,
+	}
+}
+
+func (r *servingRun) Run(s *openfunction.Serving) error {
+	log := r.log.WithName("Run").
+		WithValues("Serving", fmt.Sprintf("%s/%s", s.Namespace, s.Name))
+
+	if err := r.Clean(s); err != nil {
+		log.Error(err, "Clean failed")
+		return err
+	}
+
+	rand.Seed(time.Now().UnixNano())
+	name := fmt.Sprintf("%s-kedahttp-%s", s.Name, rand.String(5))
+	interceptor := fmt.Sprintf("%s-interceptor", name)
+	host := fmt.Sprintf("%s.%s", name, s.Namespace)
+
+	objs := []client.Object{
+		r.createDeployment(s, name),
+		workload.NewService(s, name),
+		r.createInterceptorService(s, interceptor),
+		r.createHTTPScaledObject(s, name, host),
+	}
+	if err := workload.Create(r.ctx, r.Client, r.scheme, log, s, objs...); err != nil {
+		return err
+	}
+
+	if s.Status.ResourceRef == nil {
+		s.Status.ResourceRef = make(map[string]string)
+	}
+
+	s.Status.ResourceRef[deploymentName] = name
+	s.Status.ResourceRef[serviceName] = name
+	s.Status.ResourceRef[interceptorName] = interceptor
+	s.Status.ResourceRef[httpScaledObjectName] = name
+
+	// The requests go through the interceptor, which forwards them to the function according to the host.
+	s.Status.Service = interceptor
+	s.Status.ServicePort = getInterceptor(s).Port
+	s.Status.ServiceHost = host
+
+	return nil
+}
+
+func (r *servingRun) Clean(s *openfunction.Serving) error {
+	log := r.log.WithName("Clean").
+		WithValues("Serving", fmt.Sprintf("%s/%s", s.Namespace, s.Name))
+
+	httpScaledObjectList := &unstructured.UnstructuredList{}
+	httpScaledObjectList.SetGroupVersionKind(httpScaledObjectGVK.GroupVersion().WithKind(httpScaledObjectGVK.Kind + "List"))
+
+	// Nothing need to be cleaned for the HTTPScaledObject if the KEDA HTTP add-on is not installed.
+	return workload.Clean(r.ctx, r.Client, log, s,
+		httpScaledObjectList,
+		&corev1.ServiceList{},
+		&appsv1.DeploymentList{})
+}
+
+func (r *servingRun) Result(s *openfunction.Serving) (string, error) {
+	log := r.log.WithName("Result").
+		WithValues("Serving", fmt.Sprintf("%s/%s", s.Namespace, s.Name))
+
+	deploy := &appsv1.Deployment{}
+	if err := r.Get(r.ctx, client.ObjectKey{Name: getName(s, deploymentName), Namespace: s.Namespace}, deploy); err != nil {
+		log.Error(err, "Failed to get Deployment", "Deployment", getName(s, deploymentName))
+		return "", err
+	}
+
+	if res := workload.DeploymentResult(deploy); res != "" {
+		return res, nil
+	}
+
+	// The deployment may have been scaled to zero by KEDA, which is only trusted once the HTTPScaledObject is ready,
+	// otherwise a deployment scaled to zero before any pod was ready would be taken as running.
+	if !workload.DeploymentAvailable(deploy) || deploy.Status.ReadyReplicas > 0 {
+		return "", nil
+	}
+
+	ready, err := r.httpScaledObjectReady(s)
+	if err != nil {
+		log.Error(err, "Failed to get HTTPScaledObject", "HTTPScaledObject", getName(s, httpScaledObjectName))
+		return "", err
+	}
+	if ready {
+		return openfunction.Running, nil
+	}
+
+	return "", nil
+}
+
+func (r *servingRun) httpScaledObjectReady(s *openfunction.Serving) (bool, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(httpScaledObjectGVK)
+	if err := r.Get(r.ctx, client.ObjectKey{Name: getName(s, httpScaledObjectName), Namespace: s.Namespace}, obj); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, util.IgnoreNotFound(err)
+	}
+
+	conditions, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil {
+		return false, err
+	}
+
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if cond["type"] == httpScaledObjectReady && cond["status"] == string(corev1.ConditionTrue) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// The deployment starts with one replica to make sure the function is able to run,
+// then it is scaled by KEDA according to the pending requests.
+func (r *servingRun) createDeployment(s *openfunction.Serving, name string) *appsv1.Deployment {
+
+	return workload.NewDeployment(s, name, 1)
+}
+
+// The interceptor lives in the namespace of KEDA, an ExternalName service makes it reachable
+// from the ingress of the function, which can only route to the services in its own namespace.
+func (r *servingRun) createInterceptorService(s *openfunction.Serving, name string) *corev1.Service {
+
+	interceptor := getInterceptor(s)
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: s.Namespace,
+			Labels: map[string]string{
+				constants.ServingLabel: s.Name,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: config.Get().ServiceHost(interceptor.Name, interceptor.Namespace),
+			Ports: []corev1.ServicePort{
+				{
+					Name:     "http",
+					Protocol: corev1.ProtocolTCP,
+					Port:     interceptor.Port,
+				},
+			},
+		},
+	}
+}
+
+func (r *servingRun) createHTTPScaledObject(s *openfunction.Serving, name string, host string) *unstructured.Unstructured {
+
+	targetPendingRequests := int64(defaultTargetPendingRequests)
+	minReplicas := int64(defaultMinReplicas)
+	maxReplicas := int64(defaultMaxReplicas)
+	if k := s.Spec.KedaHTTP; k != nil {
+		if k.TargetPendingRequests != nil {
+			targetPendingRequests = int64(*k.TargetPendingRequests)
+		}
+		if k.MinReplicas != nil {
+			minReplicas = int64(*k.MinReplicas)
+		}
+		if k.MaxReplicas != nil {
+			maxReplicas = int64(*k.MaxReplicas)
+		}
+	}
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"host":                  host,
+				"targetPendingRequests": targetPendingRequests,
+				"scaleTargetRef": map[string]interface{}{
+					"deployment": name,
+					"service":    name,
+					"port":       int64(workload.ServicePort),
+				},
+				"replicas": map[string]interface{}{
+					"min": minReplicas,
+					"max": maxReplicas,
+				},
+			},
+		},
+	}
+	obj.SetGroupVersionKind(httpScaledObjectGVK)
+	obj.SetName(name)
+	obj.SetNamespace(s.Namespace)
+	obj.SetLabels(map[string]string{
+		constants.ServingLabel: s.Name,
+	})
+
+	return obj
+}
+
+func getInterceptor(s *openfunction.Serving) openfunction.KedaHTTPInterceptor {
+	if s.Spec.KedaHTTP != nil && s.Spec.KedaHTTP.Interceptor != nil {
+		return *s.Spec.KedaHTTP.Interceptor
+	}
+
+	return openfunction.KedaHTTPInterceptor{
+		Name:      defaultInterceptorName,
+		Namespace: defaultInterceptorNamespace,
+		Port:      defaultInterceptorPort,
+	}
+}
+
+func getName(s *openfunction.Serving, key string) string {
+	if s.Status.ResourceRef == nil {
+		return ""
+	}
+
+	return s.Status.ResourceRef[key]
+}
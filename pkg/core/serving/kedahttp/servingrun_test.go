@@ -0,0 +1,125 @@
+package kedahttp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+	"github.com/openfunction/pkg/config"
+)
+
+func TestResult(t *testing.T) {
+	available := appsv1.DeploymentCondition{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}
+
+	tests := []struct {
+		name             string
+		readyReplicas    int32
+		conditions       []appsv1.DeploymentCondition
+		httpScaledObject string
+		want             string
+	}{
+		{
+			name:          "pods ready",
+			readyReplicas: 1,
+			conditions:    []appsv1.DeploymentCondition{available},
+			want:          openfunction.Running,
+		},
+		{
+			name:       "scaled to zero before the HTTPScaledObject is created",
+			conditions: []appsv1.DeploymentCondition{available},
+			want:       "",
+		},
+		{
+			name:             "scaled to zero before the HTTPScaledObject is ready",
+			conditions:       []appsv1.DeploymentCondition{available},
+			httpScaledObject: "False",
+			want:             "",
+		},
+		{
+			name:             "scaled to zero by KEDA",
+			conditions:       []appsv1.DeploymentCondition{available},
+			httpScaledObject: "True",
+			want:             openfunction.Running,
+		},
+		{
+			name:             "not available",
+			httpScaledObject: "True",
+			want:             "",
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	scheme.AddKnownTypeWithName(httpScaledObjectGVK, &unstructured.Unstructured{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := []client.Object{
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "serving-kedahttp-abcde", Namespace: "default"},
+					Status: appsv1.DeploymentStatus{
+						ReadyReplicas: tt.readyReplicas,
+						Conditions:    tt.conditions,
+					},
+				},
+			}
+			if tt.httpScaledObject != "" {
+				obj := &unstructured.Unstructured{Object: map[string]interface{}{
+					"status": map[string]interface{}{
+						"conditions": []interface{}{
+							map[string]interface{}{"type": httpScaledObjectReady, "status": tt.httpScaledObject},
+						},
+					},
+				}}
+				obj.SetGroupVersionKind(httpScaledObjectGVK)
+				obj.SetName("serving-kedahttp-abcde")
+				obj.SetNamespace("default")
+				objs = append(objs, obj)
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			r := NewServingRun(context.Background(), c, scheme, logr.Discard())
+
+			s := &openfunction.Serving{
+				ObjectMeta: metav1.ObjectMeta{Name: "serving", Namespace: "default"},
+				Status: openfunction.ServingStatus{
+					ResourceRef: map[string]string{
+						deploymentName:       "serving-kedahttp-abcde",
+						httpScaledObjectName: "serving-kedahttp-abcde",
+					},
+				},
+			}
+			got, err := r.Result(s)
+			if err != nil {
+				t.Fatalf("Result() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Result() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterceptorService(t *testing.T) {
+	c := config.Default()
+	c.ClusterDomain = "example.internal"
+	config.Set(c)
+	defer config.Set(config.Default())
+
+	r := &servingRun{}
+	svc := r.createInterceptorService(&openfunction.Serving{ObjectMeta: metav1.ObjectMeta{Name: "serving", Namespace: "default"}}, "interceptor")
+	if want := "keda-add-ons-http-interceptor-proxy.keda.svc.example.internal"; svc.Spec.ExternalName != want {
+		t.Errorf("ExternalName = %s, want %s", svc.Spec.ExternalName, want)
+	}
+}
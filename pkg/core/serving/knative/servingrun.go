@@ -8,6 +8,7 @@ import (
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/rand"
@@ -16,13 +17,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	openfunction "github.com/openfunction/apis/core/v1alpha2"
+	"github.com/openfunction/pkg/constants"
 	"github.com/openfunction/pkg/core"
 	"github.com/openfunction/pkg/util"
 )
 
 const (
-	servingLabel = "openfunction.io/serving"
-
 	knativeService = "serving.knative.dev/service"
 
 	defaultVersion = "latest"
@@ -58,6 +58,14 @@ func (r *servingRun) Run(s *openfunction.Serving) error {
 	}
 
 	service := r.createService(s)
+	if s.Spec.Traffic != nil {
+		traffic, err := r.desiredTraffic(s)
+		if err != nil {
+			log.Error(err, "Failed to resolve the traffic of Service", "Service", service.Name)
+			return err
+		}
+		service.Spec.Traffic = traffic
+	}
 	service.SetOwnerReferences(nil)
 	if err := ctrl.SetControllerReference(s, service, r.scheme); err != nil {
 		log.Error(err, "Failed to SetControllerReference for Service", "Service", service.Name)
@@ -86,7 +94,7 @@ func (r *servingRun) Clean(s *openfunction.Serving) error {
 		WithValues("Serving", fmt.Sprintf("%s/%s", s.Namespace, s.Name))
 
 	services := &kservingv1.ServiceList{}
-	if err := r.List(r.ctx, services, client.InNamespace(s.Namespace), client.MatchingLabels{servingLabel: s.Name}); err != nil {
+	if err := r.List(r.ctx, services, client.InNamespace(s.Namespace), client.MatchingLabels{constants.ServingLabel: s.Name}); err != nil {
 		return err
 	}
 
@@ -127,6 +135,72 @@ func (r *servingRun) Result(s *openfunction.Serving) (string, error) {
 	}
 }
 
+// SplitTraffic routes the traffic of the Knative service between its latest revision
+// and the revision of the stable serving, according to `spec.traffic` of the serving.
+func (r *servingRun) SplitTraffic(s *openfunction.Serving) (bool, error) {
+	log := r.log.WithName("SplitTraffic").
+		WithValues("Serving", fmt.Sprintf("%s/%s", s.Namespace, s.Name))
+
+	service := &kservingv1.Service{}
+	key := client.ObjectKey{Namespace: s.Namespace, Name: getName(s, knativeService)}
+	if err := r.Get(r.ctx, key, service); err != nil {
+		log.Error(err, "Failed to get Service", "Service", key.Name)
+		return false, err
+	}
+
+	traffic, err := r.desiredTraffic(s)
+	if err != nil {
+		log.Error(err, "Failed to resolve the traffic of Service", "Service", service.Name)
+		return false, err
+	}
+
+	if !equality.Semantic.DeepEqual(service.Spec.Traffic, traffic) {
+		service.Spec.Traffic = traffic
+		if err := r.Update(r.ctx, service); err != nil {
+			log.Error(err, "Failed to update the traffic of Service", "Service", service.Name)
+			return false, err
+		}
+		log.V(1).Info("Service traffic updated", "Service", service.Name)
+		return false, nil
+	}
+
+	return service.Status.ObservedGeneration == service.Generation && service.IsReady(), nil
+}
+
+// The traffic targets of the Knative service, the revision of the stable serving receives
+// the traffic which is not routed to the latest revision.
+func (r *servingRun) desiredTraffic(s *openfunction.Serving) ([]kservingv1.TrafficTarget, error) {
+	latest := true
+	if s.Spec.Traffic == nil {
+		percent := int64(100)
+		return []kservingv1.TrafficTarget{{LatestRevision: &latest, Percent: &percent}}, nil
+	}
+
+	stable := &openfunction.Serving{}
+	if err := r.Get(r.ctx, client.ObjectKey{Namespace: s.Namespace, Name: s.Spec.Traffic.StableServing}, stable); err != nil {
+		return nil, err
+	}
+
+	stableService := &kservingv1.Service{}
+	key := client.ObjectKey{Namespace: s.Namespace, Name: getName(stable, knativeService)}
+	if err := r.Get(r.ctx, key, stableService); err != nil {
+		return nil, err
+	}
+
+	revision := stableService.Status.LatestReadyRevisionName
+	if revision == "" {
+		return nil, fmt.Errorf("service %s of the stable serving %s has no ready revision", key.Name, stable.Name)
+	}
+
+	notLatest := false
+	canaryPercent := int64(s.Spec.Traffic.CanaryWeight)
+	stablePercent := 100 - canaryPercent
+	return []kservingv1.TrafficTarget{
+		{RevisionName: revision, LatestRevision: &notLatest, Percent: &stablePercent},
+		{LatestRevision: &latest, Percent: &canaryPercent},
+	}, nil
+}
+
 func (r *servingRun) createService(s *openfunction.Serving) *kservingv1.Service {
 
 	template := s.Spec.Template
@@ -193,7 +267,7 @@ func (r *servingRun) createService(s *openfunction.Serving) *kservingv1.Service
 			Name:      serviceName,
 			Namespace: s.Namespace,
 			Labels: map[string]string{
-				servingLabel: s.Name,
+				constants.ServingLabel: s.Name,
 			},
 		},
 		Spec: kservingv1.ServiceSpec{
@@ -0,0 +1,90 @@
+package knative
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+)
+
+func TestDesiredTraffic(t *testing.T) {
+	latest, notLatest := true, false
+	percent := func(p int64) *int64 { return &p }
+
+	tests := []struct {
+		name     string
+		traffic  *openfunction.ServingTraffic
+		revision string
+		want     []kservingv1.TrafficTarget
+		wantErr  bool
+	}{
+		{
+			name: "all traffic to the latest revision",
+			want: []kservingv1.TrafficTarget{{LatestRevision: &latest, Percent: percent(100)}},
+		},
+		{
+			name:     "split with the stable revision",
+			traffic:  &openfunction.ServingTraffic{StableServing: "serving-stable", CanaryWeight: 30},
+			revision: "serving-stable-ksvc-abcde-latest",
+			want: []kservingv1.TrafficTarget{
+				{RevisionName: "serving-stable-ksvc-abcde-latest", LatestRevision: &notLatest, Percent: percent(70)},
+				{LatestRevision: &latest, Percent: percent(30)},
+			},
+		},
+		{
+			name:    "stable revision not ready",
+			traffic: &openfunction.ServingTraffic{StableServing: "serving-stable", CanaryWeight: 30},
+			wantErr: true,
+		},
+		{
+			name:    "stable serving not found",
+			traffic: &openfunction.ServingTraffic{StableServing: "serving-missing", CanaryWeight: 30},
+			wantErr: true,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := openfunction.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := kservingv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stable := &openfunction.Serving{
+				ObjectMeta: metav1.ObjectMeta{Name: "serving-stable", Namespace: "default"},
+				Status: openfunction.ServingStatus{
+					ResourceRef: map[string]string{knativeService: "serving-stable-ksvc-abcde"},
+				},
+			}
+			service := &kservingv1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "serving-stable-ksvc-abcde", Namespace: "default"},
+			}
+			service.Status.LatestReadyRevisionName = tt.revision
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(stable, service).Build()
+			r := NewServingRun(context.Background(), c, scheme, logr.Discard()).(*servingRun)
+
+			s := &openfunction.Serving{
+				ObjectMeta: metav1.ObjectMeta{Name: "serving-canary", Namespace: "default"},
+				Spec:       openfunction.ServingSpec{Traffic: tt.traffic},
+			}
+			got, err := r.desiredTraffic(s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("desiredTraffic() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("desiredTraffic() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -20,13 +20,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	openfunction "github.com/openfunction/apis/core/v1alpha2"
+	"github.com/openfunction/pkg/config"
 	"github.com/openfunction/pkg/constants"
 	"github.com/openfunction/pkg/core"
 	"github.com/openfunction/pkg/util"
 )
 
 const (
-	servingLabel        = "openfunction.io/serving"
 	openfunctionManaged = "openfunction.io/managed"
 	runtimeLabel        = "runtime"
 
@@ -119,7 +119,7 @@ func (r *servingRun) Clean(s *openfunction.Serving) error {
 
 	list := func(lists []client.ObjectList) error {
 		for _, list := range lists {
-			if err := r.List(r.ctx, list, client.InNamespace(s.Namespace), client.MatchingLabels{servingLabel: s.Name}); err != nil {
+			if err := r.List(r.ctx, list, client.InNamespace(s.Namespace), client.MatchingLabels{constants.ServingLabel: s.Name}); err != nil {
 				return err
 			}
 		}
@@ -230,9 +230,9 @@ func (r *servingRun) Result(s *openfunction.Serving) (string, error) {
 func (r *servingRun) generateWorkload(s *openfunction.Serving) client.Object {
 
 	labels := map[string]string{
-		openfunctionManaged: "true",
-		servingLabel:        s.Name,
-		runtimeLabel:        string(openfunction.OpenFuncAsync),
+		openfunctionManaged:    "true",
+		constants.ServingLabel: s.Name,
+		runtimeLabel:           string(openfunction.OpenFuncAsync),
 	}
 
 	selector := &metav1.LabelSelector{
@@ -246,7 +246,7 @@ func (r *servingRun) generateWorkload(s *openfunction.Serving) client.Object {
 		replicas = *s.Spec.OpenFuncAsync.Keda.ScaledObject.MinReplicaCount
 	}
 
-	var port int32 = 8080
+	port := config.Get().ServingPort
 	if s.Spec.Port != nil {
 		port = *s.Spec.Port
 	}
@@ -262,7 +262,7 @@ func (r *servingRun) generateWorkload(s *openfunction.Serving) client.Object {
 	}
 
 	// The dapr protocol must equal to the protocol of function framework.
-	annotations[daprAPPProtocol] = "grpc"
+	annotations[daprAPPProtocol] = config.Get().DaprAppProtocol
 	// The dapr port must equal the function port.
 	annotations[daprAPPPort] = fmt.Sprintf("%d", port)
 
@@ -408,9 +408,9 @@ func (r *servingRun) createScaler(s *openfunction.Serving, workload runtime.Obje
 				GenerateName: fmt.Sprintf("%s-scaler-", s.Name),
 				Namespace:    s.Namespace,
 				Labels: map[string]string{
-					openfunctionManaged: "true",
-					servingLabel:        s.Name,
-					runtimeLabel:        string(openfunction.OpenFuncAsync),
+					openfunctionManaged:    "true",
+					constants.ServingLabel: s.Name,
+					runtimeLabel:           string(openfunction.OpenFuncAsync),
 				},
 			},
 			Spec: kedav1alpha1.ScaledJobSpec{
@@ -436,9 +436,9 @@ func (r *servingRun) createScaler(s *openfunction.Serving, workload runtime.Obje
 				GenerateName: fmt.Sprintf("%s-scaler-", s.Name),
 				Namespace:    s.Namespace,
 				Labels: map[string]string{
-					openfunctionManaged: "true",
-					servingLabel:        s.Name,
-					runtimeLabel:        string(openfunction.OpenFuncAsync),
+					openfunctionManaged:    "true",
+					constants.ServingLabel: s.Name,
+					runtimeLabel:           string(openfunction.OpenFuncAsync),
 				},
 			},
 			Spec: kedav1alpha1.ScaledObjectSpec{
@@ -516,8 +516,8 @@ func (r *servingRun) createComponents(s *openfunction.Serving) error {
 				GenerateName: fmt.Sprintf("%s-component-%s-", s.Name, name),
 				Namespace:    s.Namespace,
 				Labels: map[string]string{
-					openfunctionManaged: "true",
-					servingLabel:        s.Name,
+					openfunctionManaged:    "true",
+					constants.ServingLabel: s.Name,
 				},
 			},
 		}
@@ -583,7 +583,7 @@ func createFunctionContext(s *openfunction.Serving) string {
 		rt = openfunctioncontext.Runtime(*s.Spec.Runtime)
 	}
 
-	var port int32 = 8080
+	port := config.Get().ServingPort
 	if s.Spec.Port != nil {
 		port = *s.Spec.Port
 	}
@@ -0,0 +1,163 @@
+package plain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+	"github.com/openfunction/pkg/constants"
+	"github.com/openfunction/pkg/core"
+	"github.com/openfunction/pkg/core/serving/workload"
+)
+
+const (
+	deploymentName = "Plain/deployment"
+	serviceName    = "Plain/service"
+	hpaName        = "Plain/hpa"
+
+	defaultTargetCPUUtilizationPercentage = 80
+)
+
+type servingRun struct {
+	client.Client
+	ctx    context.Context
+	log    logr.Logger
+	scheme *runtime.Scheme
+}
+
+func Registry() []client.Object {
+	return []client.Object{&appsv1.Deployment{}, &corev1.Service{}, &autoscalingv1.HorizontalPodAutoscaler{}}
+}
+
+func NewServingRun(ctx context.Context, c client.Client, scheme *runtime.Scheme, log logr.Logger) core.ServingRun {
+	return &servingRun{
+		c,
+		ctx,
+		log.WithName("Plain"),
+		scheme,
+	}
+}
+
+func (r *servingRun) Run(s *openfunction.Serving) error {
+	log := r.log.WithName("Run").
+		WithValues("Serving", fmt.Sprintf("%s/%s", s.Namespace, s.Name))
+
+	if err := r.Clean(s); err != nil {
+		log.Error(err, "Clean failed")
+		return err
+	}
+
+	rand.Seed(time.Now().UnixNano())
+	name := fmt.Sprintf("%s-plain-%s", s.Name, rand.String(5))
+
+	objs := []client.Object{r.createDeployment(s, name), workload.NewService(s, name)}
+	if hpa := r.createHPA(s, name); hpa != nil {
+		objs = append(objs, hpa)
+	}
+
+	if err := workload.Create(r.ctx, r.Client, r.scheme, log, s, objs...); err != nil {
+		return err
+	}
+
+	if s.Status.ResourceRef == nil {
+		s.Status.ResourceRef = make(map[string]string)
+	}
+
+	s.Status.ResourceRef[deploymentName] = name
+	s.Status.ResourceRef[serviceName] = name
+	if len(objs) > 2 {
+		s.Status.ResourceRef[hpaName] = name
+	}
+	s.Status.Service = name
+
+	return nil
+}
+
+func (r *servingRun) Clean(s *openfunction.Serving) error {
+	log := r.log.WithName("Clean").
+		WithValues("Serving", fmt.Sprintf("%s/%s", s.Namespace, s.Name))
+
+	return workload.Clean(r.ctx, r.Client, log, s,
+		&autoscalingv1.HorizontalPodAutoscalerList{},
+		&corev1.ServiceList{},
+		&appsv1.DeploymentList{})
+}
+
+func (r *servingRun) Result(s *openfunction.Serving) (string, error) {
+	log := r.log.WithName("Result").
+		WithValues("Serving", fmt.Sprintf("%s/%s", s.Namespace, s.Name))
+
+	deploy := &appsv1.Deployment{}
+	if err := r.Get(r.ctx, client.ObjectKey{Name: getName(s, deploymentName), Namespace: s.Namespace}, deploy); err != nil {
+		log.Error(err, "Failed to get Deployment", "Deployment", getName(s, deploymentName))
+		return "", err
+	}
+
+	return workload.DeploymentResult(deploy), nil
+}
+
+// The deployment runs the minimum replicas of the autoscaling if it is set, then it is scaled by the HPA.
+func (r *servingRun) createDeployment(s *openfunction.Serving, name string) *appsv1.Deployment {
+
+	replicas := int32(1)
+	if plain := s.Spec.Plain; plain != nil {
+		if plain.Autoscaling != nil && plain.Autoscaling.MinReplicas != nil {
+			replicas = *plain.Autoscaling.MinReplicas
+		} else if plain.Replicas != nil {
+			replicas = *plain.Replicas
+		}
+	}
+
+	return workload.NewDeployment(s, name, replicas)
+}
+
+func (r *servingRun) createHPA(s *openfunction.Serving, name string) *autoscalingv1.HorizontalPodAutoscaler {
+
+	if s.Spec.Plain == nil || s.Spec.Plain.Autoscaling == nil {
+		return nil
+	}
+
+	autoscaling := s.Spec.Plain.Autoscaling
+	target := int32(defaultTargetCPUUtilizationPercentage)
+	if autoscaling.TargetCPUUtilizationPercentage != nil {
+		target = *autoscaling.TargetCPUUtilizationPercentage
+	}
+
+	return &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: s.Namespace,
+			Labels: map[string]string{
+				constants.ServingLabel: s.Name,
+			},
+		},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       name,
+			},
+			MinReplicas:                    autoscaling.MinReplicas,
+			MaxReplicas:                    autoscaling.MaxReplicas,
+			TargetCPUUtilizationPercentage: &target,
+		},
+	}
+}
+
+func getName(s *openfunction.Serving, key string) string {
+	if s.Status.ResourceRef == nil {
+		return ""
+	}
+
+	return s.Status.ResourceRef[key]
+}
@@ -1,15 +1,18 @@
 package serving
 
 import (
-	"sigs.k8s.io/controller-runtime/pkg/client"
-
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+	"github.com/openfunction/pkg/core"
+	"github.com/openfunction/pkg/core/serving/kedahttp"
 	"github.com/openfunction/pkg/core/serving/knative"
 	"github.com/openfunction/pkg/core/serving/openfuncasync"
+	"github.com/openfunction/pkg/core/serving/plain"
 )
 
-func Registry() []client.Object {
-	var objs []client.Object
-	objs = append(objs, knative.Registry()...)
-	objs = append(objs, openfuncasync.Registry()...)
-	return objs
+// Register the built-in runtimes, import this package to make them available.
+func init() {
+	core.RegisterServingRun(string(openfunction.Knative), knative.NewServingRun, knative.Registry()...)
+	core.RegisterServingRun(string(openfunction.OpenFuncAsync), openfuncasync.NewServingRun, openfuncasync.Registry()...)
+	core.RegisterServingRun(string(openfunction.Plain), plain.NewServingRun, plain.Registry()...)
+	core.RegisterServingRun(string(openfunction.KedaHTTP), kedahttp.NewServingRun, kedahttp.Registry()...)
 }
@@ -0,0 +1,263 @@
+// Package workload holds the resources shared by the runtimes which run the function as a Deployment
+// behind a Service, such as the Plain and the KedaHTTP runtime.
+package workload
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+	"github.com/openfunction/pkg/config"
+	"github.com/openfunction/pkg/constants"
+	"github.com/openfunction/pkg/core"
+	"github.com/openfunction/pkg/util"
+)
+
+const (
+	// ServicePort is the port of the Service, it is 80 as the Knative service does,
+	// so that the ingress can route to all runtimes in the same way.
+	ServicePort = 80
+
+	// The reason of the Progressing condition when the deployment fails to progress within the deadline.
+	progressDeadlineExceeded = "ProgressDeadlineExceeded"
+)
+
+// Port returns the port the function listens on.
+func Port(s *openfunction.Serving) int32 {
+	if s.Spec.Port != nil {
+		return *s.Spec.Port
+	}
+
+	return config.Get().ServingPort
+}
+
+// NewDeployment creates the Deployment which runs the function with the given replicas.
+func NewDeployment(s *openfunction.Serving, name string, replicas int32) *appsv1.Deployment {
+
+	template := &corev1.PodSpec{}
+	if s.Spec.Template != nil {
+		template = s.Spec.Template.DeepCopy()
+	}
+
+	if s.Spec.ImageCredentials != nil {
+		template.ImagePullSecrets = append(template.ImagePullSecrets, *s.Spec.ImageCredentials)
+	}
+
+	var container *corev1.Container
+	for index := range template.Containers {
+		if template.Containers[index].Name == core.FunctionContainer {
+			container = &template.Containers[index]
+		}
+	}
+
+	appended := false
+	if container == nil {
+		container = &corev1.Container{
+			Name:            core.FunctionContainer,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+		}
+		appended = true
+	}
+
+	container.Image = s.Spec.Image
+
+	// Unlike Knative, nothing tells the function which port to listen on, so pass it through `PORT`.
+	port := Port(s)
+	container.Ports = append(container.Ports, corev1.ContainerPort{
+		Name:          "http",
+		ContainerPort: port,
+		Protocol:      corev1.ProtocolTCP,
+	})
+	container.Env = append(container.Env, corev1.EnvVar{
+		Name:  "PORT",
+		Value: strconv.Itoa(int(port)),
+	})
+
+	if s.Spec.Params != nil {
+		for k, v := range s.Spec.Params {
+			container.Env = append(container.Env, corev1.EnvVar{
+				Name:  k,
+				Value: v,
+			})
+		}
+	}
+
+	// The pod should not receive traffic before the function is listening.
+	if container.ReadinessProbe == nil {
+		container.ReadinessProbe = &corev1.Probe{
+			Handler: corev1.Handler{
+				TCPSocket: &corev1.TCPSocketAction{
+					Port: intstr.FromInt(int(port)),
+				},
+			},
+		}
+	}
+
+	if appended {
+		template.Containers = append(template.Containers, *container)
+	}
+
+	labels := map[string]string{
+		constants.ServingLabel: s.Name,
+	}
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: s.Namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: *template,
+			},
+		},
+	}
+}
+
+// NewService creates the Service which routes to the pods of the function.
+func NewService(s *openfunction.Serving, name string) *corev1.Service {
+
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: s.Namespace,
+			Labels: map[string]string{
+				constants.ServingLabel: s.Name,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeClusterIP,
+			Selector: map[string]string{
+				constants.ServingLabel: s.Name,
+			},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       ServicePort,
+					TargetPort: intstr.FromInt(int(Port(s))),
+				},
+			},
+		},
+	}
+}
+
+// Create creates the resources of the serving, which is set as their controller.
+func Create(ctx context.Context, c client.Client, scheme *runtime.Scheme, log logr.Logger, s *openfunction.Serving, objs ...client.Object) error {
+	for _, obj := range objs {
+		kind := kindOf(obj)
+		if err := ctrl.SetControllerReference(s, obj, scheme); err != nil {
+			log.Error(err, "Failed to SetControllerReference", "Kind", kind, "Name", obj.GetName())
+			return err
+		}
+
+		if err := c.Create(ctx, obj); err != nil {
+			log.Error(err, "Failed to Create", "Kind", kind, "Name", obj.GetName())
+			return err
+		}
+
+		log.V(1).Info("Resource created", "Kind", kind, "Name", obj.GetName())
+	}
+
+	return nil
+}
+
+// Clean deletes the resources created by the serving, the lists give the types of the resources.
+// The types which are not installed in the cluster are skipped.
+func Clean(ctx context.Context, c client.Client, log logr.Logger, s *openfunction.Serving, lists ...client.ObjectList) error {
+	for _, list := range lists {
+		if err := c.List(ctx, list, client.InNamespace(s.Namespace), client.MatchingLabels{constants.ServingLabel: s.Name}); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return err
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			obj, ok := item.(client.Object)
+			if !ok || !strings.HasPrefix(obj.GetName(), s.Name) {
+				continue
+			}
+
+			if err := c.Delete(context.Background(), obj); util.IgnoreNotFound(err) != nil {
+				return err
+			}
+			log.V(1).Info("Delete", "Kind", kindOf(obj), "Name", obj.GetName())
+		}
+	}
+
+	return nil
+}
+
+// DeploymentAvailable reports whether the status of the deployment is up to date and the deployment is available.
+func DeploymentAvailable(deploy *appsv1.Deployment) bool {
+	if deploy.Status.ObservedGeneration < deploy.Generation {
+		return false
+	}
+
+	for _, cond := range deploy.Status.Conditions {
+		if cond.Type == appsv1.DeploymentAvailable {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// DeploymentResult gets the serving result from the deployment.
+// The serving is running once the deployment is available and at least one pod is ready,
+// it failed if the deployment did not progress within the deadline.
+func DeploymentResult(deploy *appsv1.Deployment) string {
+	// The status has not caught up with the spec yet.
+	if deploy.Status.ObservedGeneration < deploy.Generation {
+		return ""
+	}
+
+	for _, cond := range deploy.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing &&
+			cond.Status == corev1.ConditionFalse &&
+			cond.Reason == progressDeadlineExceeded {
+			return openfunction.Failed
+		}
+	}
+
+	if DeploymentAvailable(deploy) && deploy.Status.ReadyReplicas > 0 {
+		return openfunction.Running
+	}
+
+	return ""
+}
+
+func kindOf(obj client.Object) string {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.GetKind()
+	}
+
+	return fmt.Sprintf("%T", obj)
+}
@@ -0,0 +1,129 @@
+package workload
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+	"github.com/openfunction/pkg/constants"
+)
+
+func TestDeploymentResult(t *testing.T) {
+	available := appsv1.DeploymentCondition{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}
+	unavailable := appsv1.DeploymentCondition{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse}
+	exceeded := appsv1.DeploymentCondition{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: progressDeadlineExceeded}
+
+	tests := []struct {
+		name          string
+		generation    int64
+		observed      int64
+		conditions    []appsv1.DeploymentCondition
+		readyReplicas int32
+		want          string
+		wantAvailable bool
+	}{
+		{
+			name:          "available with ready pods",
+			generation:    1,
+			observed:      1,
+			conditions:    []appsv1.DeploymentCondition{available},
+			readyReplicas: 1,
+			want:          openfunction.Running,
+			wantAvailable: true,
+		},
+		{
+			name:          "available without ready pods",
+			generation:    1,
+			observed:      1,
+			conditions:    []appsv1.DeploymentCondition{available},
+			want:          "",
+			wantAvailable: true,
+		},
+		{
+			name:          "status out of date",
+			generation:    2,
+			observed:      1,
+			conditions:    []appsv1.DeploymentCondition{available},
+			readyReplicas: 1,
+			want:          "",
+		},
+		{
+			name:          "not available",
+			generation:    1,
+			observed:      1,
+			conditions:    []appsv1.DeploymentCondition{unavailable},
+			readyReplicas: 1,
+			want:          "",
+		},
+		{
+			name:       "progress deadline exceeded",
+			generation: 1,
+			observed:   1,
+			conditions: []appsv1.DeploymentCondition{unavailable, exceeded},
+			want:       openfunction.Failed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deploy := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: tt.generation},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: tt.observed,
+					Conditions:         tt.conditions,
+					ReadyReplicas:      tt.readyReplicas,
+				},
+			}
+			if got := DeploymentResult(deploy); got != tt.want {
+				t.Errorf("DeploymentResult() = %q, want %q", got, tt.want)
+			}
+			if got := DeploymentAvailable(deploy); got != tt.wantAvailable {
+				t.Errorf("DeploymentAvailable() = %v, want %v", got, tt.wantAvailable)
+			}
+		})
+	}
+}
+
+func TestClean(t *testing.T) {
+	labels := map[string]string{constants.ServingLabel: "serving-a"}
+	objs := []client.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "serving-a-plain-abcde", Namespace: "default", Labels: labels}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "serving-a-plain-abcde", Namespace: "default", Labels: labels}},
+		// Not created by the serving even if it carries the label.
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default", Labels: labels}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "serving-b-plain-abcde", Namespace: "default", Labels: map[string]string{constants.ServingLabel: "serving-b"}}},
+	}
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(objs...).Build()
+
+	s := &openfunction.Serving{ObjectMeta: metav1.ObjectMeta{Name: "serving-a", Namespace: "default"}}
+	if err := Clean(context.Background(), c, logr.Discard(), s, &corev1.ServiceList{}, &appsv1.DeploymentList{}); err != nil {
+		t.Fatalf("Clean() error = %v", err)
+	}
+
+	deployments := &appsv1.DeploymentList{}
+	services := &corev1.ServiceList{}
+	for _, list := range []client.ObjectList{deployments, services} {
+		if err := c.List(context.Background(), list); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(deployments.Items) != 0 {
+		t.Errorf("deployments = %d, want 0", len(deployments.Items))
+	}
+	var names []string
+	for _, svc := range services.Items {
+		names = append(names, svc.Name)
+	}
+	if len(names) != 2 || names[0] != "other" || names[1] != "serving-b-plain-abcde" {
+		t.Errorf("services = %v, want [other serving-b-plain-abcde]", names)
+	}
+}
@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+)
+
+const (
+	namespace = "openfunction"
+
+	KindBuilder = "builder"
+	KindServing = "serving"
+)
+
+var (
+	buildDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "build_duration_seconds",
+			Help:      "Duration of function builds, partitioned by build strategy and result.",
+			Buckets:   []float64{10, 30, 60, 120, 180, 300, 600, 900, 1200, 1800, 3600},
+		},
+		[]string{"strategy", "result"},
+	)
+
+	servingReadyDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "serving_ready_duration_seconds",
+			Help:      "Time from the creation of a serving until it is running, partitioned by runtime.",
+			Buckets:   []float64{1, 5, 10, 20, 30, 60, 120, 300, 600},
+		},
+		[]string{"runtime"},
+	)
+
+	timeouts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "timeouts_total",
+			Help:      "Number of builders and servings which timed out.",
+		},
+		[]string{"kind"},
+	)
+
+	domainUpdateErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "domain_update_errors_total",
+			Help:      "Number of errors when updating the resources of a domain.",
+		},
+		[]string{"domain"},
+	)
+
+	ingressUpdateErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "ingress_update_errors_total",
+			Help:      "Number of errors when updating the ingress of functions, partitioned by namespace.",
+		},
+		[]string{"namespace"},
+	)
+
+	functionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "functions"),
+		"Number of functions, partitioned by build state and serving state.",
+		[]string{"build_state", "serving_state"},
+		nil,
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(buildDuration, servingReadyDuration, timeouts, domainUpdateErrors, ingressUpdateErrors)
+}
+
+// ObserveBuild records the duration and result of a completed build.
+func ObserveBuild(strategy string, result string, duration time.Duration) {
+	buildDuration.WithLabelValues(strategy, result).Observe(duration.Seconds())
+}
+
+// ObserveServingReady records the time a serving takes to be running.
+func ObserveServingReady(runtime string, duration time.Duration) {
+	servingReadyDuration.WithLabelValues(runtime).Observe(duration.Seconds())
+}
+
+// IncTimeout counts a timeout of a builder or a serving.
+func IncTimeout(kind string) {
+	timeouts.WithLabelValues(kind).Inc()
+}
+
+// IncDomainUpdateError counts an error when updating the resources of a domain.
+func IncDomainUpdateError(domain string) {
+	domainUpdateErrors.WithLabelValues(domain).Inc()
+}
+
+// IncIngressUpdateError counts an error when updating the ingress of a function.
+func IncIngressUpdateError(namespace string) {
+	ingressUpdateErrors.WithLabelValues(namespace).Inc()
+}
+
+// RegisterFunctionCollector registers a collector which counts functions by state when the metrics are scraped.
+func RegisterFunctionCollector(c client.Reader) error {
+	return metrics.Registry.Register(&functionCollector{reader: c})
+}
+
+type functionCollector struct {
+	reader client.Reader
+}
+
+func (c *functionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- functionsDesc
+}
+
+func (c *functionCollector) Collect(ch chan<- prometheus.Metric) {
+	fnList := &openfunction.FunctionList{}
+	if err := c.reader.List(context.Background(), fnList); err != nil {
+		ch <- prometheus.NewInvalidMetric(functionsDesc, err)
+		return
+	}
+
+	type key struct {
+		build   string
+		serving string
+	}
+
+	counts := make(map[key]int)
+	for _, fn := range fnList.Items {
+		k := key{}
+		if fn.Status.Build != nil {
+			k.build = fn.Status.Build.State
+		}
+		if fn.Status.Serving != nil {
+			k.serving = fn.Status.Serving.State
+		}
+		counts[k]++
+	}
+
+	for k, count := range counts {
+		ch <- prometheus.MustNewConstMetric(functionsDesc, prometheus.GaugeValue, float64(count), k.build, k.serving)
+	}
+}
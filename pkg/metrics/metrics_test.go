@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	openfunction "github.com/openfunction/apis/core/v1alpha2"
+)
+
+func newFunction(name string, build string, serving string) *openfunction.Function {
+	fn := &openfunction.Function{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	if build != "" {
+		fn.Status.Build = &openfunction.Condition{State: build}
+	}
+	if serving != "" {
+		fn.Status.Serving = &openfunction.Condition{State: serving}
+	}
+
+	return fn
+}
+
+func TestFunctionCollector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := openfunction.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newFunction("a", openfunction.Succeeded, openfunction.Running),
+		newFunction("b", openfunction.Succeeded, openfunction.Running),
+		newFunction("c", openfunction.Failed, ""),
+		newFunction("d", "", ""),
+	).Build()
+
+	want := `
+# HELP openfunction_functions Number of functions, partitioned by build state and serving state.
+# TYPE openfunction_functions gauge
+openfunction_functions{build_state="",serving_state=""} 1
+openfunction_functions{build_state="Failed",serving_state=""} 1
+openfunction_functions{build_state="Succeeded",serving_state="Running"} 2
+`
+	if err := testutil.CollectAndCompare(&functionCollector{reader: reader}, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCounters(t *testing.T) {
+	IncTimeout(KindBuilder)
+	IncTimeout(KindServing)
+	IncTimeout(KindServing)
+	IncDomainUpdateError("openfunction")
+	IncIngressUpdateError("default")
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{name: "builder timeouts", got: testutil.ToFloat64(timeouts.WithLabelValues(KindBuilder)), want: 1},
+		{name: "serving timeouts", got: testutil.ToFloat64(timeouts.WithLabelValues(KindServing)), want: 2},
+		{name: "domain update errors", got: testutil.ToFloat64(domainUpdateErrors.WithLabelValues("openfunction")), want: 1},
+		{name: "ingress update errors", got: testutil.ToFloat64(ingressUpdateErrors.WithLabelValues("default")), want: 1},
+		{name: "ingress update errors of other namespaces", got: testutil.ToFloat64(ingressUpdateErrors.WithLabelValues("other")), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistograms(t *testing.T) {
+	ObserveBuild("openfunction", openfunction.Succeeded, 45*time.Second)
+	ObserveServingReady("Knative", 3*time.Second)
+
+	want := `
+# HELP openfunction_build_duration_seconds Duration of function builds, partitioned by build strategy and result.
+# TYPE openfunction_build_duration_seconds histogram
+openfunction_build_duration_seconds_bucket{result="Succeeded",strategy="openfunction",le="10"} 0
+openfunction_build_duration_seconds_bucket{result="Succeeded",strategy="openfunction",le="30"} 0
+openfunction_build_duration_seconds_bucket{result="Succeeded",strategy="openfunction",le="60"} 1
+openfunction_build_duration_seconds_bucket{result="Succeeded",strategy="openfunction",le="120"} 1
+openfunction_build_duration_seconds_bucket{result="Succeeded",strategy="openfunction",le="180"} 1
+openfunction_build_duration_seconds_bucket{result="Succeeded",strategy="openfunction",le="300"} 1
+openfunction_build_duration_seconds_bucket{result="Succeeded",strategy="openfunction",le="600"} 1
+openfunction_build_duration_seconds_bucket{result="Succeeded",strategy="openfunction",le="900"} 1
+openfunction_build_duration_seconds_bucket{result="Succeeded",strategy="openfunction",le="1200"} 1
+openfunction_build_duration_seconds_bucket{result="Succeeded",strategy="openfunction",le="1800"} 1
+openfunction_build_duration_seconds_bucket{result="Succeeded",strategy="openfunction",le="3600"} 1
+openfunction_build_duration_seconds_bucket{result="Succeeded",strategy="openfunction",le="+Inf"} 1
+openfunction_build_duration_seconds_sum{result="Succeeded",strategy="openfunction"} 45
+openfunction_build_duration_seconds_count{result="Succeeded",strategy="openfunction"} 1
+`
+	if err := testutil.CollectAndCompare(buildDuration, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+
+	want = `
+# HELP openfunction_serving_ready_duration_seconds Time from the creation of a serving until it is running, partitioned by runtime.
+# TYPE openfunction_serving_ready_duration_seconds histogram
+openfunction_serving_ready_duration_seconds_bucket{runtime="Knative",le="1"} 0
+openfunction_serving_ready_duration_seconds_bucket{runtime="Knative",le="5"} 1
+openfunction_serving_ready_duration_seconds_bucket{runtime="Knative",le="10"} 1
+openfunction_serving_ready_duration_seconds_bucket{runtime="Knative",le="20"} 1
+openfunction_serving_ready_duration_seconds_bucket{runtime="Knative",le="30"} 1
+openfunction_serving_ready_duration_seconds_bucket{runtime="Knative",le="60"} 1
+openfunction_serving_ready_duration_seconds_bucket{runtime="Knative",le="120"} 1
+openfunction_serving_ready_duration_seconds_bucket{runtime="Knative",le="300"} 1
+openfunction_serving_ready_duration_seconds_bucket{runtime="Knative",le="600"} 1
+openfunction_serving_ready_duration_seconds_bucket{runtime="Knative",le="+Inf"} 1
+openfunction_serving_ready_duration_seconds_sum{runtime="Knative"} 3
+openfunction_serving_ready_duration_seconds_count{runtime="Knative"} 1
+`
+	if err := testutil.CollectAndCompare(servingReadyDuration, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
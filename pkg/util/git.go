@@ -0,0 +1,123 @@
+package util
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var commitRegexp = regexp.MustCompile("^[0-9a-f]{40}$")
+
+// gitClient lists the refs of the repositories, the timeout keeps an unresponsive server from blocking the reconcile.
+var gitClient = &http.Client{Timeout: 30 * time.Second}
+
+// ResolveGitRevision resolves the revision (branch, tag or ref) of a git repository to a commit SHA,
+// the default branch is resolved if the revision is empty.
+// Only repositories served over http(s) are supported, the refs are listed with the git smart http protocol.
+func ResolveGitRevision(ctx context.Context, url string, revision string, username string, password string) (string, error) {
+	// A commit never moves.
+	if commitRegexp.MatchString(revision) {
+		return revision, nil
+	}
+
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return "", fmt.Errorf("unsupported git url %s, only http(s) is supported", url)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(url, "/")+"/info/refs?service=git-upload-pack", nil)
+	if err != nil {
+		return "", err
+	}
+	if username != "" || password != "" {
+		req.SetBasicAuth(username, password)
+	}
+
+	resp, err := gitClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to list refs of %s: %s", url, resp.Status)
+	}
+
+	refs, err := readRefs(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var candidates []string
+	if revision == "" {
+		candidates = []string{"HEAD"}
+	} else {
+		candidates = []string{
+			"refs/heads/" + revision,
+			"refs/tags/" + revision + "^{}",
+			"refs/tags/" + revision,
+			revision,
+		}
+	}
+
+	for _, candidate := range candidates {
+		if sha, ok := refs[candidate]; ok {
+			return sha, nil
+		}
+	}
+
+	return "", fmt.Errorf("revision %s not found in %s", revision, url)
+}
+
+// Read the refs advertised by the server, the response is a sequence of pkt-lines,
+// each line is prefixed with its length in 4 hex digits and a length of "0000" is a flush packet.
+func readRefs(r io.Reader) (map[string]string, error) {
+	refs := make(map[string]string)
+	reader := bufio.NewReader(r)
+	for {
+		prefix := make([]byte, 4)
+		if _, err := io.ReadFull(reader, prefix); err != nil {
+			if err == io.EOF {
+				return refs, nil
+			}
+			return nil, err
+		}
+
+		length, err := strconv.ParseUint(string(prefix), 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pkt-line length %q", prefix)
+		}
+
+		// Flush packet.
+		if length == 0 {
+			continue
+		}
+		if length < 4 {
+			return nil, fmt.Errorf("invalid pkt-line length %q", prefix)
+		}
+
+		line := make([]byte, length-4)
+		if _, err := io.ReadFull(reader, line); err != nil {
+			return nil, err
+		}
+
+		s := strings.TrimSuffix(string(line), "\n")
+		if strings.HasPrefix(s, "#") {
+			continue
+		}
+		if i := strings.IndexByte(s, 0); i >= 0 {
+			s = s[:i]
+		}
+
+		fields := strings.SplitN(s, " ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		refs[fields[1]] = fields[0]
+	}
+}
@@ -0,0 +1,183 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	mainCommit = "1111111111111111111111111111111111111111"
+	tagObject  = "2222222222222222222222222222222222222222"
+	tagCommit  = "3333333333333333333333333333333333333333"
+	pullCommit = "4444444444444444444444444444444444444444"
+)
+
+func pktLine(s string) string {
+	return fmt.Sprintf("%04x%s", len(s)+4, s)
+}
+
+// The refs advertised by a git smart http server.
+var advertisement = pktLine("# service=git-upload-pack\n") + "0000" +
+	pktLine(mainCommit+" HEAD\x00multi_ack symref=HEAD:refs/heads/main\n") +
+	pktLine(mainCommit+" refs/heads/main\n") +
+	pktLine(pullCommit+" refs/pull/1/head\n") +
+	pktLine(tagObject+" refs/tags/v1\n") +
+	pktLine(tagCommit+" refs/tags/v1^{}\n") +
+	"0000"
+
+func TestReadRefs(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "advertisement",
+			body: advertisement,
+			want: map[string]string{
+				"HEAD":             mainCommit,
+				"refs/heads/main":  mainCommit,
+				"refs/pull/1/head": pullCommit,
+				"refs/tags/v1":     tagObject,
+				"refs/tags/v1^{}":  tagCommit,
+			},
+		},
+		{
+			name: "empty",
+			body: "",
+			want: map[string]string{},
+		},
+		{
+			name:    "invalid length",
+			body:    "zzzz",
+			wantErr: true,
+		},
+		{
+			name:    "length shorter than the prefix",
+			body:    "0002",
+			wantErr: true,
+		},
+		{
+			name:    "truncated line",
+			body:    "0030" + mainCommit,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readRefs(strings.NewReader(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readRefs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readRefs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveGitRevision(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repo.git/info/refs" || r.URL.Query().Get("service") != "git-upload-pack" {
+			http.NotFound(w, r)
+			return
+		}
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-git-upload-pack-advertisement")
+		fmt.Fprint(w, advertisement)
+	}))
+	defer server.Close()
+
+	url := server.URL + "/repo.git"
+	tests := []struct {
+		name     string
+		url      string
+		revision string
+		password string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "default branch",
+			url:      url,
+			password: "token",
+			want:     mainCommit,
+		},
+		{
+			name:     "branch",
+			url:      url,
+			revision: "main",
+			password: "token",
+			want:     mainCommit,
+		},
+		{
+			name:     "annotated tag is peeled",
+			url:      url,
+			revision: "v1",
+			password: "token",
+			want:     tagCommit,
+		},
+		{
+			name:     "ref",
+			url:      url + "/",
+			revision: "refs/pull/1/head",
+			password: "token",
+			want:     pullCommit,
+		},
+		{
+			name:     "commit is not resolved",
+			url:      "git@github.com:openfunction/samples.git",
+			revision: pullCommit,
+			want:     pullCommit,
+		},
+		{
+			name:     "missing revision",
+			url:      url,
+			revision: "dev",
+			password: "token",
+			wantErr:  true,
+		},
+		{
+			name:     "unauthorized",
+			url:      url,
+			revision: "main",
+			password: "wrong",
+			wantErr:  true,
+		},
+		{
+			name:     "not found",
+			url:      server.URL + "/other.git",
+			revision: "main",
+			password: "token",
+			wantErr:  true,
+		},
+		{
+			name:     "unsupported url",
+			url:      "git@github.com:openfunction/samples.git",
+			revision: "main",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveGitRevision(context.Background(), tt.url, tt.revision, "user", tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveGitRevision() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveGitRevision() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}